@@ -0,0 +1,36 @@
+// Package cache locates the files this program keeps between runs, like the ledger and the run history.
+// Puzzle inputs are kept by the input package.
+package cache
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DirEnvVar names an environment variable that overrides the cache directory.
+const DirEnvVar = "AOC_CACHE_DIR"
+
+// Dir returns the cache directory, creating it if needed.
+func Dir() (string, error) {
+	dir := os.Getenv(DirEnvVar)
+	if dir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(userDir, "adventofcode-go")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns the path of a file in the cache directory.
+func Path(elem ...string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
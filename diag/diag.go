@@ -0,0 +1,131 @@
+// Package diag collects diagnostic output from solvers so it can be shown separately from their answers.
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the verbosity of a diagnostic message. Higher levels are more verbose.
+type Level int
+
+// Verbosity levels.
+const (
+	// Quiet discards all messages when used as a sink's level.
+	Quiet Level = iota
+	// Info is for messages worth seeing on a normal run, like the diagnostic codes printed by intcode programs.
+	Info
+	// Debug is for messages that are only useful when something's wrong.
+	Debug
+)
+
+func (l Level) String() string {
+	switch l {
+	case Quiet:
+		return "quiet"
+	case Info:
+		return "info"
+	case Debug:
+		return "debug"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// Entry is a single captured message.
+type Entry struct {
+	Level Level
+	Time  time.Time
+	Msg   string
+}
+
+// Sink captures messages at or below its level and drops the rest.
+// It's safe for concurrent use, and a nil *Sink discards everything.
+type Sink struct {
+	mu      sync.Mutex
+	level   Level
+	entries []Entry
+	notify  func(Entry)
+}
+
+// New returns a sink that keeps messages at or below level.
+func New(level Level) *Sink {
+	return &Sink{level: level}
+}
+
+// Level returns the level of the most verbose messages the sink keeps.
+func (s *Sink) Level() Level {
+	if s == nil {
+		return Quiet
+	}
+	return s.level
+}
+
+// Enabled reports whether messages at level l would be kept.
+// Use it to skip building expensive messages.
+func (s *Sink) Enabled(l Level) bool {
+	return s != nil && l > Quiet && l <= s.level
+}
+
+// Logf captures a message at level l.
+func (s *Sink) Logf(l Level, format string, args ...interface{}) {
+	if !s.Enabled(l) {
+		return
+	}
+	e := Entry{Level: l, Time: time.Now(), Msg: fmt.Sprintf(format, args...)}
+	s.mu.Lock()
+	s.entries = append(s.entries, e)
+	notify := s.notify
+	s.mu.Unlock()
+	if notify != nil {
+		notify(e)
+	}
+}
+
+// Notify sets a function to call with each message as it's captured, e.g. to stream messages while a solver runs.
+// The function may be called concurrently if the solver logs from several goroutines.
+func (s *Sink) Notify(f func(Entry)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notify = f
+}
+
+// Infof captures a message at the Info level.
+func (s *Sink) Infof(format string, args ...interface{}) {
+	s.Logf(Info, format, args...)
+}
+
+// Debugf captures a message at the Debug level.
+func (s *Sink) Debugf(format string, args ...interface{}) {
+	s.Logf(Debug, format, args...)
+}
+
+// Entries returns a copy of the messages captured so far.
+func (s *Sink) Entries() []Entry {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+// Writer returns a writer that captures each line written to it as a message at level l.
+func (s *Sink) Writer(l Level) io.Writer {
+	return lineWriter{s, l}
+}
+
+type lineWriter struct {
+	s *Sink
+	l Level
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.s.Logf(w.l, "%s", line)
+	}
+	return len(p), nil
+}
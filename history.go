@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jzimbel/adventofcode-go/history"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// runHistory charts a day's runtime across the commits it has been run at.
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	part := fs.Int("part", 0, "show runs that solved only this part; 0 shows runs that solved both")
+	impl := fs.String("impl", "", "show runs of the implementation with this `name` instead of the default one")
+	machine := fs.String("machine", history.Machine(), "show runs from the machine with this `name`; empty shows every machine's")
+	last := fs.Int("n", 30, "show at most this many of the latest commits")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s history [flags] <year> <day>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	year, err1 := strconv.Atoi(fs.Arg(0))
+	day, err2 := strconv.Atoi(fs.Arg(1))
+	if err1 != nil || err2 != nil {
+		fmt.Fprintln(os.Stderr, "Year and day arguments must be integers.")
+		return 2
+	}
+
+	runs, err := history.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	k := solutions.Key{Year: year, Day: day}
+	points := history.ByCommit(runs, history.Filter{Key: k, Part: *part, Impl: *impl, Machine: *machine})
+	if len(points) == 0 {
+		fmt.Fprintf(os.Stderr, "No runs of year %d, day %d in the history match.\n", year, day)
+		return 1
+	}
+	if *last > 0 && len(points) > *last {
+		points = points[len(points)-*last:]
+	}
+
+	values := make([]float64, len(points))
+	for i := range points {
+		values[i] = float64(points[i].Median)
+	}
+	fmt.Printf("%v median runtime by commit, oldest first\n", k)
+	fmt.Println(history.Sparkline(values))
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "commit\tfirst run\truns\tmedian\t")
+	for _, p := range points {
+		commit := p.Commit
+		if commit == "" {
+			commit = "unknown"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%v\t\n", commit, p.First.Local().Format("2006-01-02 15:04"), p.Runs, p.Median)
+	}
+	w.Flush()
+	return 0
+}
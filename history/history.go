@@ -0,0 +1,181 @@
+// Package history keeps a record of every solver run, so that changes in runtime can be traced to commits.
+//
+// Runs are appended as JSON lines to history.jsonl in the cache directory. The file is never rewritten.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/cache"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+)
+
+// Run is the record of one solver run.
+type Run struct {
+	Time time.Time `json:"time"`
+	Year int       `json:"year"`
+	Day  int       `json:"day"`
+	// Part is the part that was solved, or 0 if both were.
+	Part int    `json:"part,omitempty"`
+	Impl string `json:"impl"`
+	// Part1 and Part2 are the answers found, or nil for a part that wasn't solved.
+	Part1    *string       `json:"part1,omitempty"`
+	Part2    *string       `json:"part2,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// Commit is the revision of the code that ran, with a "-dirty" suffix if it had uncommitted changes.
+	// It's empty if it isn't known.
+	Commit  string `json:"commit,omitempty"`
+	Machine string `json:"machine,omitempty"`
+}
+
+// Key returns the puzzle the run solved.
+func (r *Run) Key() solutions.Key {
+	return solutions.Key{Year: r.Year, Day: r.Day}
+}
+
+// NewRun fills in a record of a run that just finished, including the current commit and machine.
+func NewRun(k solutions.Key, part int, impl string, s *solutions.Solution, d time.Duration) Run {
+	if impl == "" {
+		impl = solutions.DefaultImpl
+	}
+	r := Run{
+		Time:     time.Now().UTC(),
+		Year:     k.Year,
+		Day:      k.Day,
+		Part:     part,
+		Impl:     impl,
+		Duration: d,
+		Commit:   Commit(),
+		Machine:  Machine(),
+	}
+	if s.Part1 != nil {
+		a := fmt.Sprint(s.Part1)
+		r.Part1 = &a
+	}
+	if s.Part2 != nil {
+		a := fmt.Sprint(s.Part2)
+		r.Part2 = &a
+	}
+	return r
+}
+
+// Commit returns the revision this program was built from. Builds that don't embed it fall back to
+// asking git about the repository the source is in.
+func Commit() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var rev, modified string
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				rev = s.Value
+			case "vcs.modified":
+				modified = s.Value
+			}
+		}
+		if rev != "" {
+			if len(rev) > 12 {
+				rev = rev[:12]
+			}
+			if modified == "true" {
+				rev += "-dirty"
+			}
+			return rev
+		}
+	}
+
+	rev, err := git("rev-parse", "--short=12", "HEAD")
+	if err != nil {
+		return ""
+	}
+	if status, err := git("status", "--porcelain", "--untracked-files=no"); err == nil && status != "" {
+		rev += "-dirty"
+	}
+	return rev
+}
+
+func git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = examples.Root()
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Machine returns the name of the machine this is running on.
+func Machine() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+func path() (string, error) {
+	return cache.Path("history.jsonl")
+}
+
+// Append adds runs to the history.
+func Append(runs ...Run) error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range runs {
+		if err := enc.Encode(&runs[i]); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	// a single write keeps concurrent appends from interleaving lines
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads every run in the history, oldest first. A missing history is empty.
+func Load() ([]Run, error) {
+	p, err := path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var r Run
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", p, line, err)
+		}
+		runs = append(runs, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Time.Before(runs[j].Time) })
+	return runs, nil
+}
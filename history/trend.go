@@ -0,0 +1,88 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// Filter selects runs of one puzzle.
+type Filter struct {
+	Key solutions.Key
+	// Part is the part that was solved, or 0 for runs that solved both.
+	Part int
+	// Impl is the implementation that ran. Empty means the default one.
+	Impl string
+	// Machine is the machine the runs happened on. Empty means any machine.
+	Machine string
+}
+
+// Match reports whether the filter selects a run.
+func (f *Filter) Match(r *Run) bool {
+	impl := f.Impl
+	if impl == "" {
+		impl = solutions.DefaultImpl
+	}
+	return r.Key() == f.Key && r.Part == f.Part && r.Impl == impl && (f.Machine == "" || r.Machine == f.Machine)
+}
+
+// Point sums up the runs of one commit.
+type Point struct {
+	Commit string        `json:"commit"`
+	Runs   int           `json:"runs"`
+	Median time.Duration `json:"median"`
+	// First is when the commit was first run.
+	First time.Time `json:"first"`
+}
+
+// ByCommit groups the runs that the filter selects by commit, ordered by when each commit was first run.
+// Runs are expected oldest first, as Load returns them.
+func ByCommit(runs []Run, f Filter) []Point {
+	var points []Point
+	durations := make(map[string][]time.Duration)
+	for i := range runs {
+		r := &runs[i]
+		if !f.Match(r) {
+			continue
+		}
+		if _, ok := durations[r.Commit]; !ok {
+			points = append(points, Point{Commit: r.Commit, First: r.Time})
+		}
+		durations[r.Commit] = append(durations[r.Commit], r.Duration)
+	}
+	for i := range points {
+		ds := durations[points[i].Commit]
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		points[i].Runs, points[i].Median = len(ds), ds[len(ds)/2]
+	}
+	return points
+}
+
+// bars are the levels of a sparkline, lowest first.
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of bars scaled between the smallest and the largest.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(bars)-1))
+		}
+		line[i] = bars[level]
+	}
+	return string(line)
+}
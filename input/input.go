@@ -113,6 +113,19 @@ func Get(year int, day int) (string, error) {
 	}
 }
 
+// Cached loads an input that has already been downloaded, without downloading it if it hasn't, and reports
+// whether there was one.
+func Cached(year int, day int) (string, bool, error) {
+	if !Downloaded(year, day) {
+		return "", false, nil
+	}
+	in, err := readInputFile(getInputFilePath(year, day))
+	if err != nil {
+		return "", false, err
+	}
+	return in, true, nil
+}
+
 func getInputFilePath(year int, day int) string {
 	return filepath.Join(inputsDirPath, fmt.Sprintf("%d-%02d", year, day))
 }
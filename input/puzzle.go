@@ -0,0 +1,91 @@
+package input
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var puzzlesDirPath = filepath.Join(projectTempDirPath, "puzzles")
+
+// SessionSaved reports whether the user's session id has been saved, so that downloads won't ask for it.
+func SessionSaved() bool {
+	_, err := os.Stat(userSessionIDPath)
+	return err == nil
+}
+
+// Downloaded reports whether a puzzle's input has already been downloaded.
+func Downloaded(year int, day int) bool {
+	_, err := os.Stat(getInputFilePath(year, day))
+	return err == nil
+}
+
+// GetPuzzle returns a puzzle's description as plain text. The page is downloaded once and saved,
+// unless refresh is set; refresh after solving part 1 to get the description of part 2.
+func GetPuzzle(year int, day int, refresh bool) (string, error) {
+	path := filepath.Join(puzzlesDirPath, fmt.Sprintf("%d-%02d.html", year, day))
+	page, err := ioutil.ReadFile(path)
+	if refresh || os.IsNotExist(err) {
+		if page, err = downloadPuzzle(year, day); err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(puzzlesDirPath, 0777); err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(path, page, 0666); err != nil {
+			return "", err
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return puzzleText(string(page)), nil
+}
+
+func downloadPuzzle(year int, day int) ([]byte, error) {
+	sessionID, err := getUserSessionID()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d/day/%d", aocURL, year, day), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "session", Value: sessionID})
+	req.Header.Add("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("server responded with a non-200 status code: %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+var (
+	articlePattern = regexp.MustCompile(`(?s)<article[^>]*>(.*?)</article>`)
+	tagPattern     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines     = regexp.MustCompile(`\n{3,}`)
+	blockTags      = strings.NewReplacer(
+		"</h2>", "\n\n", "</p>", "\n\n", "</pre>", "\n", "<li>", "  - ", "</li>", "\n", "</ul>", "\n",
+	)
+)
+
+// puzzleText pulls the descriptions of a puzzle's parts out of its page and renders them as plain text.
+func puzzleText(page string) string {
+	var parts []string
+	for _, m := range articlePattern.FindAllStringSubmatch(page, -1) {
+		text := tagPattern.ReplaceAllString(blockTags.Replace(m[1]), "")
+		parts = append(parts, strings.TrimSpace(html.UnescapeString(text)))
+	}
+	return blankLines.ReplaceAllString(strings.Join(parts, "\n\n"), "\n\n")
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jzimbel/adventofcode-go/color"
+	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
+)
+
+// intcodeCommands are the subcommands of the intcode command, for working with intcode programs directly.
+var intcodeCommands = map[string]func(args []string) int{
+	"disasm": runDisasm,
+	"asm":    runAsm,
+	"replay": runReplay,
+	"run":    runProgram,
+}
+
+// runIntcode runs one of the intcode subcommands.
+func runIntcode(args []string) int {
+	if len(args) == 0 || intcodeCommands[args[0]] == nil {
+		names := make([]string, 0, len(intcodeCommands))
+		for name := range intcodeCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Usage: %s intcode <%s> [flags] <args>\n", os.Args[0], strings.Join(names, "|"))
+		return 2
+	}
+	return intcodeCommands[args[0]](args[1:])
+}
+
+// loadProgram reads an intcode program from a file, or from a puzzle's input if given a year and a day.
+func loadProgram(args []string) (interpreter.Program, error) {
+	var text string
+	switch len(args) {
+	case 1:
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	case 2:
+		year, err1 := strconv.Atoi(args[0])
+		day, err2 := strconv.Atoi(args[1])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("year and day arguments must be integers")
+		}
+		var err error
+		if text, err = input.Get(year, day); err != nil {
+			return nil, fmt.Errorf("failed to load puzzle input: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("give a program file, or the year and day of a puzzle whose input is one")
+	}
+	return interpreter.ParseMem(strings.TrimSpace(text)), nil
+}
+
+// runDisasm prints an intcode program as assembly.
+func runDisasm(args []string) int {
+	fs := flag.NewFlagSet("intcode disasm", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s intcode disasm <file | year day>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	p, err := loadProgram(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+	fmt.Print(interpreter.Disassemble(p))
+	return 0
+}
+
+// runAsm assembles a file of intcode assembly and prints the program.
+func runAsm(args []string) int {
+	fs := flag.NewFlagSet("intcode asm", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s intcode asm <file>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	src, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	p, err := interpreter.Assemble(string(src))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	fmt.Println(p)
+	return 0
+}
+
+// runReplay re-runs the machines in a trace written with -intcode-trace, and reports where they first diverge from it.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("intcode replay", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s intcode replay <trace>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	defer f.Close()
+	div, stats, err := interpreter.Replay(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	if div != nil {
+		fmt.Println(color.R("DIVERGED"), div)
+		return 1
+	}
+	fmt.Printf("%s %d machines matched the trace over %d events.\n", color.G("OK"), stats.Machines, stats.Events)
+	return 0
+}
+
+// patchFlags is a flag that collects addr=value changes to make to a program before it runs.
+type patchFlags map[uint]int
+
+func (p patchFlags) String() string {
+	settings := make([]string, 0, len(p))
+	for addr, v := range p {
+		settings = append(settings, fmt.Sprintf("%d=%d", addr, v))
+	}
+	return strings.Join(settings, ",")
+}
+
+func (p patchFlags) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("patch %q isn't addr=value", s)
+	}
+	addr, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 0)
+	if err != nil {
+		return fmt.Errorf("patch %q: address must be a non-negative integer", s)
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return fmt.Errorf("patch %q: value must be an integer", s)
+	}
+	p[uint(addr)] = v
+	return nil
+}
+
+// stepLimit is an observer that stops a machine once it has executed a number of instructions.
+type stepLimit struct {
+	steps, max int
+}
+
+func (l *stepLimit) Before(*interpreter.Interpreter) error {
+	if l.steps == l.max {
+		return fmt.Errorf("hit the limit of %d steps", l.max)
+	}
+	l.steps++
+	return nil
+}
+
+func (l *stepLimit) Wrote(*interpreter.Interpreter, uint, int) {}
+func (l *stepLimit) Input(*interpreter.Interpreter, int)       {}
+func (l *stepLimit) Output(*interpreter.Interpreter, int)      {}
+
+// parseValues reads whitespace- or comma-separated integers.
+func parseValues(s string) ([]int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	values := make([]int, len(fields))
+	for j, f := range fields {
+		var err error
+		if values[j], err = strconv.Atoi(f); err != nil {
+			return nil, fmt.Errorf("input %q isn't an integer", f)
+		}
+	}
+	return values, nil
+}
+
+// asciiValues turns text into character codes, as ASCII-capable programs read it.
+func asciiValues(s string) []int {
+	values := make([]int, 0, len(s))
+	for _, c := range s {
+		values = append(values, int(c))
+	}
+	return values
+}
+
+// runProgram runs an intcode program from the shell, with input from flags, a file or stdin, and prints its output.
+func runProgram(args []string) int {
+	fs := flag.NewFlagSet("intcode run", flag.ExitOnError)
+	inputArg := fs.String("input", "", "input for the program: integers separated by commas or spaces, or text with -ascii")
+	inputFile := fs.String("input-file", "", "read input for the program from this `file`, after -input")
+	ascii := fs.Bool("ascii", false, "read input as text and write output as text, for programs that speak ASCII")
+	maxSteps := fs.Int("steps", 0, "stop the program with an error after this many instructions; 0 is no limit")
+	patches := make(patchFlags)
+	fs.Var(patches, "set", "set memory before running, as `addr=value`; repeatable")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s intcode run [flags] <file | year day>\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "Input from -input and -input-file comes first. Once it runs out, more is read from stdin as the program asks for it.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	p, err := loadProgram(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 2
+	}
+
+	var queued []int
+	parse := func(s string) error {
+		if *ascii {
+			queued = append(queued, asciiValues(s)...)
+			return nil
+		}
+		values, err := parseValues(s)
+		queued = append(queued, values...)
+		return err
+	}
+	if *inputArg != "" {
+		in := *inputArg
+		if *ascii && !strings.HasSuffix(in, "\n") {
+			// programs that read text read a line at a time
+			in += "\n"
+		}
+		if err := parse(in); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 2
+		}
+	}
+	if *inputFile != "" {
+		b, err := ioutil.ReadFile(*inputFile)
+		if err == nil {
+			err = parse(string(b))
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 2
+		}
+	}
+
+	m := interpreter.New(p, nil, nil)
+	for addr, v := range patches {
+		m.Poke(addr, v)
+	}
+	m.PushInput(queued...)
+	if *maxSteps > 0 {
+		m.Observe(&stepLimit{max: *maxSteps})
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	atLineStart := true
+	stdin := bufio.NewReader(os.Stdin)
+	for {
+		e, err := m.RunUntilEvent()
+		if err != nil {
+			out.Flush()
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		switch e {
+		case interpreter.Halted:
+			return 0
+		case interpreter.Output:
+			n, _ := m.PopOutput()
+			if *ascii && n >= 0 && n < 128 {
+				out.WriteByte(byte(n))
+				atLineStart = n == '\n'
+				break
+			}
+			// a value that isn't a character, like a puzzle's answer, goes on a line of its own
+			if !atLineStart {
+				out.WriteByte('\n')
+			}
+			fmt.Fprintln(out, n)
+			atLineStart = true
+		case interpreter.NeedInput:
+			out.Flush()
+			values, err := readInput(stdin, *ascii)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = errors.New("the program wants more input than it was given")
+				}
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				return 1
+			}
+			m.PushInput(values...)
+		}
+	}
+}
+
+// readInput reads the next line of input from stdin, as text or integers, skipping lines with no integers on them.
+func readInput(r *bufio.Reader, ascii bool) ([]int, error) {
+	for {
+		line, err := r.ReadString('\n')
+		if line == "" && err != nil {
+			return nil, err
+		}
+		if ascii {
+			if !strings.HasSuffix(line, "\n") {
+				line += "\n"
+			}
+			return asciiValues(line), nil
+		}
+		values, perr := parseValues(line)
+		if perr != nil {
+			return nil, perr
+		}
+		if len(values) > 0 {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
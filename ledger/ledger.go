@@ -0,0 +1,96 @@
+// Package ledger records the answers that have been accepted for each puzzle.
+//
+// The ledger is a JSON file in the cache directory. Tests compare solvers' answers for real inputs against it,
+// and each recorded answer counts as a star.
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/jzimbel/adventofcode-go/cache"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// Answers are the accepted answers for a puzzle. A part that hasn't been solved is nil.
+type Answers struct {
+	Part1 *string `json:"part1,omitempty"`
+	Part2 *string `json:"part2,omitempty"`
+}
+
+// Stars returns the number of parts with accepted answers.
+func (a Answers) Stars() (n int) {
+	if a.Part1 != nil {
+		n++
+	}
+	if a.Part2 != nil {
+		n++
+	}
+	return
+}
+
+// Ledger holds accepted answers keyed by puzzle. It's safe for concurrent use.
+type Ledger struct {
+	mu      sync.Mutex
+	path    string
+	answers map[string]Answers
+}
+
+// Load reads the ledger from the cache directory. A missing ledger is empty.
+func Load() (*Ledger, error) {
+	path, err := cache.Path("ledger.json")
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads a ledger from the given file. A missing file is an empty ledger.
+func LoadFile(path string) (*Ledger, error) {
+	l := &Ledger{path: path, answers: make(map[string]Answers)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &l.answers); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Get returns the accepted answers for a puzzle, and whether any have been recorded.
+func (l *Ledger) Get(k solutions.Key) (a Answers, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a, ok = l.answers[k.String()]
+	return
+}
+
+// Record marks a solution's answers as accepted and saves the ledger.
+// A part the solution didn't solve is recorded as nil.
+func (l *Ledger) Record(k solutions.Key, s *solutions.Solution) error {
+	var a Answers
+	if s.Part1 != nil {
+		p1 := fmt.Sprint(s.Part1)
+		a.Part1 = &p1
+	}
+	if s.Part2 != nil {
+		p2 := fmt.Sprint(s.Part2)
+		a.Part2 = &p2
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.answers[k.String()] = a
+	b, err := json.MarshalIndent(l.answers, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, b, 0644)
+}
@@ -1,24 +1,152 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"math/rand"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jzimbel/adventofcode-go/color"
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/history"
 	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/ledger"
 	_ "github.com/jzimbel/adventofcode-go/solutioninit"
 	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+	"github.com/jzimbel/adventofcode-go/solutions/external"
+	"github.com/jzimbel/adventofcode-go/solutions/solutiontest"
+	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
 )
 
+// verbosity is a flag that raises the diagnostic level by one each time it's given.
+type verbosity int
+
+func (v *verbosity) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+func (v *verbosity) Set(s string) error {
+	if n, err := strconv.Atoi(s); err == nil {
+		*v = verbosity(n)
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if b {
+		*v++
+	}
+	return nil
+}
+
+func (v *verbosity) IsBoolFlag() bool {
+	return true
+}
+
+// paramFlags is a flag that collects name=value overrides for solver parameters.
+type paramFlags map[string]int
+
+func (p paramFlags) String() string {
+	settings := make([]string, 0, len(p))
+	for name, v := range p {
+		settings = append(settings, fmt.Sprintf("%s=%d", name, v))
+	}
+	return strings.Join(settings, ",")
+}
+
+func (p paramFlags) Set(s string) error {
+	name, v, err := solutions.ParseParam(s)
+	if err != nil {
+		return err
+	}
+	p[name] = v
+	return nil
+}
+
+var (
+	verbose      verbosity
+	params       = make(paramFlags)
+	withExamples bool
+	check        bool
+	accept       bool
+	bench        bool
+	generateSeed int64
+	generateSize int
+	impl         string
+	crossCheck   int
+	part         int
+	cpuProfile   string
+	memProfile   string
+	traceFile    string
+	sandboxed    bool
+	memLimit     int64
+	cpuLimit     time.Duration
+	wallLimit    time.Duration
+	debug        bool
+	intcodeTrace string
+)
+
+func init() {
+	flag.Var(&verbose, "v", "show more diagnostics; repeat for more detail")
+	flag.Var(params, "param", "set a solver parameter, as `name=value`; repeatable")
+	flag.BoolVar(&withExamples, "examples", false, "run the day's stored examples instead of the real input")
+	flag.BoolVar(&check, "check", false, "check answers for examples and cached inputs against the expected ones")
+	flag.BoolVar(&accept, "accept", false, "record the answers as accepted in the ledger")
+	flag.BoolVar(&bench, "bench", false, "benchmark each part with go test -bench, which needs Go and this source tree")
+	flag.Int64Var(&generateSeed, "generate", -1, "print a synthetic input made from this `seed` instead of solving")
+	flag.IntVar(&generateSize, "size", 0, "scale of the input made by -generate; 0 is about the size of a real input")
+	flag.StringVar(&impl, "impl", "", "run the solver's alternative implementation with this `name` instead of the default one")
+	flag.IntVar(&crossCheck, "crosscheck", 0, "compare the implementations of each solver on this many generated inputs, saving shrunk disagreements as examples")
+	flag.IntVar(&part, "part", 0, "solve only this part, 1 or 2; 0 solves both")
+	flag.StringVar(&cpuProfile, "cpuprofile", "", "write a CPU profile of the solver to this `file`")
+	flag.StringVar(&memProfile, "memprofile", "", "write a profile of the solver's memory allocations to this `file`")
+	flag.StringVar(&traceFile, "trace", "", "write an execution trace of the solver to this `file`")
+	flag.BoolVar(&sandboxed, "sandbox", false, "run each day of a year in a child process, within -mem-limit, -cpu-limit and -timeout")
+	flag.Int64Var(&memLimit, "mem-limit", 2048, "memory limit of a sandboxed day, in `MiB`")
+	flag.DurationVar(&cpuLimit, "cpu-limit", time.Minute, "CPU time limit of a sandboxed day")
+	flag.DurationVar(&wallLimit, "timeout", 2*time.Minute, "wall-clock time limit of a sandboxed day")
+	flag.BoolVar(&debug, "debug", false, "run the day's intcode machines under an interactive debugger")
+	flag.StringVar(&intcodeTrace, "intcode-trace", "", "record everything the day's intcode machines do to a trace `file`, for intcode replay")
+	flag.Usage = usage
+}
+
+// commands are the subcommands, which are given as the first argument and take their own flags.
+var commands = map[string]func(args []string) int{
+	"report":  runReport,
+	"history": runHistory,
+	"profile": runProfile,
+	"serve":   runServe,
+	"tui":     runTUI,
+	"intcode": runIntcode,
+
+	// not listed in the usage; it's how -sandbox runs each day
+	sandboxChild: runSandboxChild,
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s <year> <day>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <year> [day]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s report [flags] <year>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s history [flags] <year> <day>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s profile [flags] <year> <day>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s serve [flags]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s tui\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s intcode disasm <file | year day>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s intcode asm <file>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s intcode replay <trace>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s intcode run [flags] <file | year day>\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Omit the day to run every solution for the year. Pass -h after a command for its flags.")
+	flag.PrintDefaults()
 }
 
 func getArgs() (int, int, bool) {
-	args := os.Args[1:]
-	if len(args) != 2 {
+	args := flag.Args()
+	if len(args) < 1 || len(args) > 2 {
 		return 0, 0, false
 	}
 	year, err := strconv.Atoi(args[0])
@@ -26,6 +154,9 @@ func getArgs() (int, int, bool) {
 		fmt.Fprintln(os.Stderr, "Year argument must be an integer.")
 		return 0, 0, false
 	}
+	if len(args) == 1 {
+		return year, 0, true
+	}
 	day, err := strconv.Atoi(args[1])
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Day argument must be an integer.")
@@ -44,33 +175,402 @@ func printSolution(s *solutions.Solution, year int, day int) {
 		if strings.ContainsRune(solution2, '\n') {
 			solution2 = "\n" + solution2
 		}
-		fmt.Println("Answer to part 1:", solution1)
-		fmt.Println("Answer to part 2:", solution2)
+		if part != 2 {
+			fmt.Println("Answer to part 1:", solution1)
+		}
+		if part != 1 {
+			fmt.Println("Answer to part 2:", solution2)
+		}
 	} else {
 		fmt.Fprintf(os.Stderr, "No solution for year %d, day %d yet.\n", year, day)
 	}
 }
 
+// printDiagnostics writes captured diagnostics to stderr, apart from the answers on stdout.
+func printDiagnostics(d *diag.Sink) {
+	entries := d.Entries()
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, color.Y("Diagnostics:"))
+	for _, e := range entries {
+		fmt.Fprintf(os.Stderr, "  [%s] %s\n", e.Level, e.Msg)
+	}
+}
+
+// registerExternalSolvers adds the solvers listed in the external solver config file to the registry.
+func registerExternalSolvers() error {
+	path, err := external.DefaultConfigPath()
+	if err != nil {
+		return err
+	}
+	config, err := external.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return config.Register(solutions.Registry)
+}
+
+// loadDay looks up the chosen implementation of a day's solver and loads its input, ready to solve.
+func loadDay(year int, day int, d *diag.Sink, overrides map[string]int) (entry solutions.Entry, solver solutions.Solver, env *solutions.Env, in string, err error) {
+	entry, ok := solutions.Registry.Lookup(year, day)
+	if !ok {
+		err = fmt.Errorf("could not find solution code for year %d, day %d", year, day)
+		return
+	}
+	if solver, ok = entry.Impl(impl); !ok {
+		err = fmt.Errorf("year %d, day %d has no implementation %q; it has %s",
+			year, day, impl, strings.Join(entry.ImplNames(), ", "))
+		return
+	}
+	if env, err = entry.NewEnv(d, overrides); err != nil {
+		return
+	}
+	env.Part = part
+	if in, err = input.Get(year, day); err != nil {
+		err = fmt.Errorf("failed to load puzzle input: %w", err)
+		return
+	}
+	return
+}
+
+// runDay loads the input for a day and runs its solver with the given parameter overrides.
+// Only the solver call is profiled, if profiling was asked for.
+// Runs without overrides or profiling are recorded in the history.
+func runDay(year int, day int, d *diag.Sink, overrides map[string]int) (*solutions.Solution, error) {
+	entry, solver, env, input, err := loadDay(year, day, d, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	stopProfiling, err := startProfiling()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	sol, err := solver(input, env)
+	elapsed := time.Since(start)
+	if err := stopProfiling(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write profile: %v.\n", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// runs with overrides solve a different puzzle, and profiling, debugging and tracing slow runs down,
+	// so none of those runtimes are comparable
+	if len(overrides) == 0 && !profiling() && !debug && intcodeTrace == "" {
+		if err := history.Append(history.NewRun(entry.Key, part, impl, sol, elapsed)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to record run history: %v.\n", err)
+		}
+	}
+	return sol, nil
+}
+
+// runExamples runs a day's solver against its stored examples, each with its own parameter overrides.
+func runExamples(year int, day int) (ok bool) {
+	entry, found := solutions.Registry.Lookup(year, day)
+	if !found {
+		fmt.Fprintf(os.Stderr, "Could not find solution code for year %d, day %d.\n", year, day)
+		return false
+	}
+	solver, found := entry.Impl(impl)
+	if !found {
+		fmt.Fprintf(os.Stderr, "Year %d, day %d has no implementation %q.\n", year, day, impl)
+		return false
+	}
+	// run the examples against the chosen implementation
+	entry.Solver = solver
+	exs, err := examples.Load(entry.Key)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return false
+	}
+	if len(exs) == 0 {
+		fmt.Fprintf(os.Stderr, "No examples for year %d, day %d in %s.\n", year, day, examples.Dir(entry.Key))
+		return false
+	}
+
+	ok = true
+	for i := range exs {
+		fmt.Println(color.B("Example " + exs[i].Name))
+		d := diag.New(diag.Level(verbose))
+		s, err := exs[i].Run(entry, d)
+		printDiagnostics(d)
+		if err != nil {
+			fmt.Println(color.R("FAIL:"), err)
+			ok = false
+			continue
+		}
+		printSolution(s, year, day)
+	}
+	return
+}
+
+// runChecks checks the solvers for a year, or a single day if day isn't 0,
+// against their examples and the ledger's answers for cached inputs.
+func runChecks(year int, day int) (ok bool) {
+	l, err := ledger.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return false
+	}
+
+	ok = true
+	for _, k := range solutions.Registry.Keys() {
+		if k.Year != year || (day != 0 && k.Day != day) {
+			continue
+		}
+		cases, err := solutiontest.Cases(solutions.Registry, k, l)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			ok = false
+			continue
+		}
+		for i := range cases {
+			c := &cases[i]
+			name := fmt.Sprintf("%v %s", k, c.Name)
+			if c.Skip != "" {
+				fmt.Println(color.Y("SKIP"), name+":", c.Skip)
+				continue
+			}
+			d := diag.New(diag.Level(verbose))
+			err := c.Run(d)
+			printDiagnostics(d)
+			if err != nil {
+				fmt.Println(color.R("FAIL"), name+":", err)
+				ok = false
+			} else {
+				fmt.Println(color.G("PASS"), name)
+			}
+		}
+	}
+	return
+}
+
+// runBenchmarks benchmarks each part of the solvers for a year, or a single day if day isn't 0.
+// It runs BenchmarkSolutions with go test -bench, so results can be compared with benchdiff.
+func runBenchmarks(year int, day int) (ok bool) {
+	for _, k := range solutions.Registry.Keys() {
+		if k.Year != year || (day != 0 && k.Day != day) {
+			continue
+		}
+		bms, err := solutiontest.Benchmarks(solutions.Registry, k)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		for i := range bms {
+			if bms[i].Skip != "" {
+				fmt.Fprintf(os.Stderr, "BenchmarkSolutions/%s: skipped: %s\n", bms[i].Name, bms[i].Skip)
+			}
+		}
+	}
+	cmd := solutiontest.BenchCommand(year, day)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to run the benchmarks with go test: %v.\n", err)
+		return false
+	}
+	return true
+}
+
+// runCrossChecks compares the implementations of the solvers for a year, or a single day if day isn't 0,
+// on generated inputs. Inputs they disagree on are shrunk and saved as new examples.
+func runCrossChecks(year int, day int) (ok bool) {
+	ok = true
+	for _, k := range solutions.Registry.Keys() {
+		if k.Year != year || (day != 0 && k.Day != day) {
+			continue
+		}
+		entry, _ := solutions.Registry.Lookup(k.Year, k.Day)
+		if len(entry.Impls) == 0 || entry.Generator == nil {
+			if day != 0 {
+				fmt.Println(color.Y("SKIP"), k.String()+": needs more than one implementation and a generator")
+			}
+			continue
+		}
+		dis, err := solutiontest.FindDisagreement(solutions.Registry, k, crossCheck)
+		switch {
+		case err != nil:
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			ok = false
+		case dis == nil:
+			fmt.Println(color.G("PASS"), k.String()+":", strings.Join(entry.ImplNames(), ", "), "agree")
+		default:
+			fmt.Println(color.R("FAIL"), dis)
+			ok = false
+			ex, err := dis.SaveExample()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Failed to save example:", err)
+				continue
+			}
+			fmt.Printf("Saved the shrunk input as example %s in %s. Check its expected answers.\n", ex.Name, examples.Dir(k))
+		}
+	}
+	return
+}
+
+// printGenerated prints a synthetic input for a day.
+func printGenerated(year int, day int) (ok bool) {
+	entry, found := solutions.Registry.Lookup(year, day)
+	if !found || entry.Generator == nil {
+		fmt.Fprintf(os.Stderr, "No input generator for year %d, day %d.\n", year, day)
+		return false
+	}
+	fmt.Println(entry.Generator(rand.New(rand.NewSource(generateSeed)), generateSize))
+	return true
+}
+
+// runYear runs every registered solution for a year, each in its own child process if -sandbox was given.
+// A day that crashes or goes over a limit is reported as that day's error, and the rest still run.
+// Diagnostics are suppressed unless the verbosity has been raised.
+func runYear(year int) (ok bool) {
+	ok = true
+	for _, k := range solutions.Registry.Keys() {
+		if k.Year != year {
+			continue
+		}
+		fmt.Println(color.B(fmt.Sprintf("Day %d", k.Day)))
+		d := diag.New(diag.Level(verbose))
+		var s *solutions.Solution
+		var err error
+		if sandboxed {
+			s, err = runDaySandboxed(k.Year, k.Day, d)
+		} else {
+			s, err = runDay(k.Year, k.Day, d, nil)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			ok = false
+		} else {
+			printSolution(s, k.Year, k.Day)
+		}
+		printDiagnostics(d)
+	}
+	return
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+	flag.Parse()
 	year, day, ok := getArgs()
 	if !ok {
 		usage()
 		os.Exit(1)
 	}
-	input, err := input.Get(year, day)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load puzzle input: %v.\n", err)
+	if part < 0 || part > 2 {
+		fmt.Fprintln(os.Stderr, "The -part flag must be 1, 2, or 0 for both parts.")
 		os.Exit(1)
 	}
-	solver, ok := solutions.Registry.Get(year, day)
-	if !ok {
-		fmt.Fprintf(os.Stderr, "Could not find solution code for year %d, day %d.\n", year, day)
+	if memProfile != "" {
+		// only sample allocations once the solver starts
+		runtime.MemProfileRate = 0
+	}
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		os.Exit(1)
+	}
+
+	if check {
+		if !runChecks(year, day) {
+			os.Exit(1)
+		}
+		return
+	}
+	if crossCheck > 0 {
+		if !runCrossChecks(year, day) {
+			os.Exit(1)
+		}
+		return
+	}
+	if bench {
+		if !runBenchmarks(year, day) {
+			os.Exit(1)
+		}
+		return
+	}
+	if sandboxed && (day != 0 || profiling()) {
+		fmt.Fprintln(os.Stderr, "The -sandbox flag runs a whole year, without profiling.")
+		os.Exit(1)
+	}
+	if day == 0 {
+		if len(params) > 0 || withExamples || accept || generateSeed >= 0 || impl != "" || profiling() || debug || intcodeTrace != "" {
+			fmt.Fprintln(os.Stderr, "The -param, -examples, -accept, -generate, -impl, -debug, -intcode-trace and profiling flags need a day.")
+			os.Exit(1)
+		}
+		if !runYear(year) {
+			os.Exit(1)
+		}
+		return
+	}
+	if generateSeed >= 0 {
+		if !printGenerated(year, day) {
+			os.Exit(1)
+		}
+		return
+	}
+	if withExamples {
+		if !runExamples(year, day) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if debug && intcodeTrace != "" {
+		fmt.Fprintln(os.Stderr, "The -debug and -intcode-trace flags can't be used together.")
 		os.Exit(1)
 	}
-	s, err := solver(input)
+	var dbg *interpreter.Debugger
+	if debug {
+		dbg = interpreter.NewDebugger(os.Stdin, os.Stderr)
+		interpreter.OnNew = dbg.Attach
+	}
+	var tracer *interpreter.Tracer
+	if intcodeTrace != "" {
+		f, err := os.Create(intcodeTrace)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		tracer = interpreter.NewTracer(f)
+		interpreter.OnNew = tracer.Attach
+	}
+	d := diag.New(diag.Info + diag.Level(verbose))
+	s, err := runDay(year, day, d, params)
+	printDiagnostics(d)
+	if dbg != nil && dbg.Machines() == 0 {
+		fmt.Fprintf(os.Stderr, "Year %d, day %d didn't run any intcode machines to debug.\n", year, day)
+	}
+	if tracer != nil {
+		if err := tracer.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to write intcode trace:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote a trace of %d intcode machines to %s.\n", tracer.Machines(), intcodeTrace)
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 	printSolution(s, year, day)
+
+	if accept {
+		if len(params) > 0 || impl != "" || part != 0 {
+			fmt.Fprintln(os.Stderr, "Answers found with -param, -impl or -part can't be accepted.")
+			os.Exit(1)
+		}
+		l, err := ledger.Load()
+		if err == nil {
+			err = l.Record(solutions.Key{Year: year, Day: day}, s)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to record answers:", err)
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+)
+
+// the rate at which the runtime samples memory allocations by default
+const defaultMemProfileRate = 512 * 1024
+
+// profiling reports whether any profiling flags were given.
+func profiling() bool {
+	return cpuProfile != "" || memProfile != "" || traceFile != ""
+}
+
+// startProfiling starts the CPU profile and execution trace asked for by flags, and turns on sampling for the
+// memory profile. The returned function stops them and writes the profiles out.
+func startProfiling() (stop func() error, err error) {
+	var cpuOut, traceOut *os.File
+	stop = func() (err error) {
+		keep := func(e error) {
+			if err == nil {
+				err = e
+			}
+		}
+		if cpuOut != nil {
+			pprof.StopCPUProfile()
+			keep(cpuOut.Close())
+		}
+		if traceOut != nil {
+			trace.Stop()
+			keep(traceOut.Close())
+		}
+		if memProfile != "" {
+			keep(writeAllocsProfile(memProfile))
+		}
+		return
+	}
+
+	if cpuProfile != "" {
+		if cpuOut, err = os.Create(cpuProfile); err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(cpuOut); err != nil {
+			cpuOut.Close()
+			return nil, err
+		}
+	}
+	if traceFile != "" {
+		if traceOut, err = os.Create(traceFile); err != nil {
+			stop()
+			return nil, err
+		}
+		if err := trace.Start(traceOut); err != nil {
+			traceOut.Close()
+			traceOut = nil
+			stop()
+			return nil, err
+		}
+	}
+	if memProfile != "" {
+		// sampling was turned off at startup so that the profile only shows what the solver allocates
+		runtime.MemProfileRate = defaultMemProfileRate
+	}
+	return stop, nil
+}
+
+// writeAllocsProfile writes a profile of the memory allocated since sampling started.
+func writeAllocsProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// make sure every sampled allocation is in the profile
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runProfile runs a day's solver a number of times under the CPU profiler, or the memory profiler with -mem,
+// and prints the functions that took the most time or allocated the most memory.
+func runProfile(args []string) int {
+	fs := flag.NewFlagSet("profile", flag.ExitOnError)
+	runs := fs.Int("n", 10, "run the solver this many times")
+	top := fs.Int("top", 20, "show this many of the top functions")
+	mem := fs.Bool("mem", false, "profile memory allocations instead of CPU time")
+	out := fs.String("o", "", "keep the profile in this `file` instead of a temporary one")
+	fs.StringVar(&impl, "impl", "", "profile the solver's alternative implementation with this `name`")
+	fs.IntVar(&part, "part", 0, "solve only this part, 1 or 2; 0 solves both")
+	fs.Var(params, "param", "set a solver parameter, as `name=value`; repeatable")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s profile [flags] <year> <day>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	year, err1 := strconv.Atoi(fs.Arg(0))
+	day, err2 := strconv.Atoi(fs.Arg(1))
+	if err1 != nil || err2 != nil {
+		fmt.Fprintln(os.Stderr, "Year and day arguments must be integers.")
+		return 2
+	}
+	if *runs < 1 {
+		fmt.Fprintln(os.Stderr, "The -n flag must be at least 1.")
+		return 2
+	}
+
+	path := *out
+	if path == "" {
+		dir, err := os.MkdirTemp("", "adventofcode-go-profile")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		path = filepath.Join(dir, "profile.pprof")
+	}
+	if *mem {
+		memProfile = path
+		runtime.MemProfileRate = 0
+	} else {
+		cpuProfile = path
+	}
+
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		return 1
+	}
+	_, solver, env, input, err := loadDay(year, day, diag.New(diag.Quiet), params)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	stop, err := startProfiling()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	for i := 0; i < *runs; i++ {
+		if _, err := solver(input, env); err != nil {
+			stop()
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+	}
+	if err := stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write profile: %v.\n", err)
+		return 1
+	}
+
+	pprofArgs := []string{"tool", "pprof", "-top", "-nodecount=" + strconv.Itoa(*top)}
+	if *mem {
+		pprofArgs = append(pprofArgs, "-sample_index=alloc_space")
+	}
+	pprofArgs = append(pprofArgs, path)
+	cmd := exec.Command("go", pprofArgs...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to summarize the profile with go tool pprof: %v.\n", err)
+		if *out != "" {
+			fmt.Fprintf(os.Stderr, "The profile is in %s.\n", path)
+		}
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/jzimbel/adventofcode-go/ledger"
+	"github.com/jzimbel/adventofcode-go/report"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// runReport writes a progress report for a year, as Markdown or HTML.
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "markdown", "output `format`: markdown or html")
+	redact := fs.Bool("redact", false, "hide the answers, showing only which parts are solved")
+	runs := fs.Int("runs", report.DefaultRuns, "times to run each solver on its cached input for the median runtime; 0 skips timing")
+	out := fs.String("o", "", "write the report to this `file` instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s report [flags] <year>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	year, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Year argument must be an integer.")
+		return 2
+	}
+
+	var write func(*report.Report, io.Writer) error
+	switch *format {
+	case "markdown", "md":
+		write = (*report.Report).WriteMarkdown
+	case "html":
+		write = (*report.Report).WriteHTML
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown report format %q.\n", *format)
+		return 2
+	}
+
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		return 1
+	}
+	l, err := ledger.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	rep, err := report.Build(solutions.Registry, l, year, report.Options{Redact: *redact, Runs: *runs})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	for _, d := range rep.Days {
+		if d.RunErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to time year %d day %d: %v\n", d.Key.Year, d.Key.Day, d.RunErr)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(rep, w); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Answer returns the text to show for a part's answer: the answer itself, "redacted", or "" if it isn't known.
+func (d *Day) Answer(part int, redacted bool) string {
+	a := d.Part1
+	if part == 2 {
+		a = d.Part2
+	}
+	switch {
+	case a != nil:
+		return *a
+	case redacted && d.Stars >= part:
+		return "redacted"
+	default:
+		return ""
+	}
+}
+
+// Name returns the puzzle's title, or a stand-in if it isn't known.
+func (d *Day) Name() string {
+	if d.Title == "" {
+		return fmt.Sprintf("Day %d", d.Key.Day)
+	}
+	return d.Title
+}
+
+// URL returns the address of the puzzle on the Advent of Code site.
+func (d *Day) URL() string {
+	return fmt.Sprintf("https://adventofcode.com/%d/day/%d", d.Key.Year, d.Key.Day)
+}
+
+// formatRuntime rounds a runtime to three or so significant figures.
+func formatRuntime(d Day) string {
+	switch {
+	case d.RunErr != nil:
+		return "failed"
+	case d.Runtime == 0:
+		return "–"
+	case d.Runtime >= time.Second:
+		return d.Runtime.Round(10 * time.Millisecond).String()
+	case d.Runtime >= time.Millisecond:
+		return d.Runtime.Round(10 * time.Microsecond).String()
+	default:
+		return d.Runtime.Round(time.Microsecond).String()
+	}
+}
+
+func stars(n int) string {
+	return strings.Repeat("★", n) + strings.Repeat("☆", 2-n)
+}
+
+func multiline(s string) bool {
+	return strings.ContainsRune(s, '\n')
+}
+
+var funcs = map[string]interface{}{
+	"runtime":   formatRuntime,
+	"stars":     stars,
+	"multiline": multiline,
+	"join":      strings.Join,
+	"parts":     func() []int { return []int{1, 2} },
+	// mdCell makes text safe to put in a Markdown table cell
+	"mdCell": strings.NewReplacer("|", `\|`, "\n", " ").Replace,
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(
+	`# Advent of Code {{.Year}}
+
+{{.Stars}} of {{.MaxStars}} stars
+
+| Day | Puzzle | Stars | Part 1 | Part 2 | Median runtime | Lines of code | Uses |
+| ---: | --- | --- | --- | --- | ---: | ---: | --- |
+{{- range $d := .Days}}
+| {{.Key.Day}} | [{{mdCell .Name}}]({{.URL}}) | {{stars .Stars}} |
+	{{- range parts}} {{with $d.Answer . $.Redacted}}{{if multiline .}}see below{{else if eq . "redacted"}}*redacted*{{else}}` + "`{{mdCell .}}`" + `{{end}}{{end}} |{{end}}
+	{{- ""}} {{runtime $d}} | {{if .Lines}}{{.Lines}}{{else}}–{{end}} | {{join .Uses ", "}} |
+{{- end}}
+{{- range $d := .Days}}{{range $p := parts}}{{with $d.Answer $p $.Redacted}}{{if multiline .}}
+
+### Day {{$d.Key.Day}}, part {{$p}}
+
+` + "```" + `
+{{.}}
+` + "```" + `
+{{- end}}{{end}}{{end}}{{end}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Advent of Code {{.Year}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; vertical-align: top; }
+td.num { text-align: right; }
+pre { margin: 0; line-height: 1; }
+</style>
+</head>
+<body>
+<h1>Advent of Code {{.Year}}</h1>
+<p>{{.Stars}} of {{.MaxStars}} stars</p>
+<table>
+<tr><th>Day</th><th>Puzzle</th><th>Stars</th><th>Part 1</th><th>Part 2</th><th>Median runtime</th><th>Lines of code</th><th>Uses</th></tr>
+{{- range $d := .Days}}
+<tr>
+<td class="num">{{.Key.Day}}</td>
+<td><a href="{{.URL}}">{{.Name}}</a></td>
+<td>{{stars .Stars}}</td>
+{{- range parts}}
+<td>{{with $d.Answer . $.Redacted}}{{if multiline .}}<pre>{{.}}</pre>{{else if eq . "redacted"}}<em>redacted</em>{{else}}<code>{{.}}</code>{{end}}{{end}}</td>
+{{- end}}
+<td class="num">{{runtime $d}}</td>
+<td class="num">{{if .Lines}}{{.Lines}}{{else}}–{{end}}</td>
+<td>{{join .Uses ", "}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteMarkdown writes the report as a Markdown table. Multi-line answers, like letters drawn in ASCII art,
+// follow the table in code blocks.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	return markdownTemplate.Execute(w, r)
+}
+
+// WriteHTML writes the report as a standalone HTML page.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
@@ -0,0 +1,181 @@
+// Package report summarizes the progress on a year's puzzles: each day's stars and answers from the ledger,
+// how long its solver takes, and how much code is behind it. Reports render as Markdown or HTML.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/ledger"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+)
+
+// DefaultRuns is the number of times each solver is run to find its median runtime.
+const DefaultRuns = 5
+
+// Options control what goes into a report.
+type Options struct {
+	// Redact hides the answers, leaving only whether each part has been solved.
+	Redact bool
+	// Runs is the number of times each solver is run on its cached input. 0 skips timing.
+	Runs int
+}
+
+// Day is a report's row for one puzzle.
+type Day struct {
+	Key   solutions.Key
+	Title string
+	Stars int
+	// Part1 and Part2 are the accepted answers, or nil if they aren't known or are redacted.
+	Part1 *string
+	Part2 *string
+	// Runtime is the median time the solver took on the cached input, or 0 if it wasn't timed.
+	Runtime time.Duration
+	// RunErr is why the solver couldn't be timed, if it failed.
+	RunErr error
+	// Lines is the number of lines of code in the day's package, or 0 if the solver isn't a Go package in this repo.
+	Lines int
+	// Uses are the repo's shared packages imported by the day's package, like "interpreter".
+	Uses []string
+}
+
+// Report is the progress on a year's puzzles.
+type Report struct {
+	Year     int
+	Days     []Day
+	Redacted bool
+}
+
+// Stars returns the total number of stars earned.
+func (r *Report) Stars() (n int) {
+	for i := range r.Days {
+		n += r.Days[i].Stars
+	}
+	return
+}
+
+// MaxStars returns the number of stars there are to earn for the days in the report.
+func (r *Report) MaxStars() int {
+	return 2 * len(r.Days)
+}
+
+// modulePath is the import path of the repository root.
+var modulePath = path.Dir(reflect.TypeOf(solutions.Key{}).PkgPath())
+
+// Build makes a report for every solver registered in r for the year.
+func Build(r *solutions.Solvers, l *ledger.Ledger, year int, opts Options) (*Report, error) {
+	rep := &Report{Year: year, Redacted: opts.Redact}
+	for _, k := range r.Keys() {
+		if k.Year != year {
+			continue
+		}
+		e, _ := r.Lookup(k.Year, k.Day)
+		day := Day{Key: k, Title: e.Title}
+		if answers, ok := l.Get(k); ok {
+			day.Stars = answers.Stars()
+			if !opts.Redact {
+				day.Part1, day.Part2 = answers.Part1, answers.Part2
+			}
+		}
+		if opts.Runs > 0 {
+			day.Runtime, day.RunErr = medianRuntime(e, opts.Runs)
+		}
+		var err error
+		if day.Lines, day.Uses, err = inspect(k); err != nil {
+			return nil, fmt.Errorf("%v: %w", k, err)
+		}
+		rep.Days = append(rep.Days, day)
+	}
+	return rep, nil
+}
+
+// medianRuntime runs a solver on its cached input and returns the median time it took.
+// It returns 0 without an error if the input hasn't been cached.
+func medianRuntime(e solutions.Entry, runs int) (time.Duration, error) {
+	in, ok, err := input.Cached(e.Key.Year, e.Key.Day)
+	if err != nil || !ok {
+		return 0, err
+	}
+	times := make([]time.Duration, runs)
+	for i := range times {
+		env, err := e.NewEnv(nil, nil)
+		if err != nil {
+			return 0, err
+		}
+		start := time.Now()
+		if _, err := e.Solver(in, env); err != nil {
+			return 0, err
+		}
+		times[i] = time.Since(start)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times[len(times)/2], nil
+}
+
+// inspect counts the lines of code in a day's package and lists the shared packages it imports.
+// A day without a package in the repo, like one with an external solver, has neither.
+func inspect(k solutions.Key) (lines int, uses []string, err error) {
+	dir := filepath.Join(examples.Root(), "solutions", fmt.Sprintf("y%d", k.Year), fmt.Sprintf("d%02d", k.Day))
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	imported := make(map[string]bool)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		n, err := countLines(file)
+		if err != nil {
+			return 0, nil, err
+		}
+		lines += n
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, spec := range f.Imports {
+			p, _ := strconv.Unquote(spec.Path.Value)
+			// every solver imports the registry package, so it isn't worth listing
+			if strings.HasPrefix(p, modulePath+"/") && p != modulePath+"/solutions" {
+				imported[path.Base(p)] = true
+			}
+		}
+	}
+	for name := range imported {
+		uses = append(uses, name)
+	}
+	sort.Strings(uses)
+	return
+}
+
+// countLines counts the lines in a file that aren't blank or only a comment.
+func countLines(file string) (n int, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "//") {
+			n++
+		}
+	}
+	return n, scanner.Err()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/history"
+	"github.com/jzimbel/adventofcode-go/sandbox"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// sandboxChild is the hidden command that a sandboxed run starts in a child process.
+const sandboxChild = "sandbox-child"
+
+// limits returns the sandbox limits set by flags.
+func limits() sandbox.Limits {
+	return sandbox.Limits{Memory: memLimit << 20, CPU: cpuLimit, Wall: wallLimit}
+}
+
+// runDaySandboxed runs a day's solver in a child process of this executable, within the limits set by flags.
+// The parent loads the input, so that only the solver runs in the sandbox. Runs are recorded in the history
+// with the child's own measure of the solver's runtime.
+func runDaySandboxed(year int, day int, d *diag.Sink) (*solutions.Solution, error) {
+	entry, _, env, in, err := loadDay(year, day, d, nil)
+	if err != nil {
+		return nil, err
+	}
+	sol, elapsed, err := solveSandboxed(entry.Key, impl, env, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := history.Append(history.NewRun(entry.Key, part, impl, sol, elapsed)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to record run history: %v.\n", err)
+	}
+	return sol, nil
+}
+
+// solveSandboxed solves an input with an implementation of a day's solver in a child process of this executable,
+// within the limits set by flags. The child gets the part and parameters from env, and its diagnostics are copied
+// to env.Diag once it's done. It returns the child's own measure of the solver's runtime.
+func solveSandboxed(k solutions.Key, impl string, env *solutions.Env, in string) (*solutions.Solution, time.Duration, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, 0, err
+	}
+	args := []string{
+		sandboxChild,
+		"-mem-limit", strconv.FormatInt(memLimit, 10),
+		"-cpu-limit", cpuLimit.String(),
+		"-part", strconv.Itoa(env.Part),
+		"-impl", impl,
+		// -v is a bool flag, so its value has to be attached
+		"-v=" + strconv.Itoa(int(env.Diag.Level())),
+	}
+	for name, v := range env.Params {
+		args = append(args, "-param", fmt.Sprintf("%s=%d", name, v))
+	}
+	args = append(args, strconv.Itoa(k.Year), strconv.Itoa(k.Day))
+	return sandbox.Run(context.Background(), exe, args, in, limits(), env.Diag)
+}
+
+// runSandboxChild is the child's side of runDaySandboxed. It solves the input given on stdin
+// and writes the result to stdout.
+func runSandboxChild(args []string) int {
+	fs := flag.NewFlagSet(sandboxChild, flag.ExitOnError)
+	fs.Int64Var(&memLimit, "mem-limit", 0, "memory limit in MiB")
+	fs.DurationVar(&cpuLimit, "cpu-limit", 0, "CPU time limit")
+	fs.IntVar(&part, "part", 0, "part to solve")
+	fs.StringVar(&impl, "impl", "", "implementation to run")
+	fs.Var(&verbose, "v", "diagnostic verbosity")
+	fs.Var(params, "param", "solver parameter")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: sandbox-child [flags] <year> <day>")
+		return 2
+	}
+	year, yerr := strconv.Atoi(fs.Arg(0))
+	day, derr := strconv.Atoi(fs.Arg(1))
+	if yerr != nil || derr != nil {
+		fmt.Fprintln(os.Stderr, "Year and day arguments must be integers.")
+		return 2
+	}
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		return 1
+	}
+
+	// the wall time limit is enforced by the parent
+	l := sandbox.Limits{Memory: memLimit << 20, CPU: cpuLimit}
+	err := sandbox.Serve(l, diag.Level(verbose), func(input string, d *diag.Sink) (*solutions.Solution, error) {
+		entry, ok := solutions.Registry.Lookup(year, day)
+		if !ok {
+			return nil, fmt.Errorf("could not find solution code for year %d, day %d", year, day)
+		}
+		solver, ok := entry.Impl(impl)
+		if !ok {
+			return nil, fmt.Errorf("year %d, day %d has no implementation %q", year, day, impl)
+		}
+		env, err := entry.NewEnv(d, params)
+		if err != nil {
+			return nil, err
+		}
+		env.Part = part
+		return solver(input, env)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
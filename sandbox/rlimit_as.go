@@ -0,0 +1,7 @@
+//go:build darwin || dragonfly || freebsd || netbsd || solaris
+
+package sandbox
+
+import "syscall"
+
+const memoryResource = syscall.RLIMIT_AS
@@ -0,0 +1,10 @@
+//go:build dragonfly || freebsd
+
+package sandbox
+
+import "syscall"
+
+// rlimit makes a resource limit; these systems' limits are signed.
+func rlimit(cur, max uint64) *syscall.Rlimit {
+	return &syscall.Rlimit{Cur: int64(cur), Max: int64(max)}
+}
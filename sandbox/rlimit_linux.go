@@ -0,0 +1,7 @@
+package sandbox
+
+import "syscall"
+
+// Linux counts the Go runtime's address space reservations against RLIMIT_AS, but only memory it has
+// actually mapped for writing against RLIMIT_DATA.
+const memoryResource = syscall.RLIMIT_DATA
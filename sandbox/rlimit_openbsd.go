@@ -0,0 +1,6 @@
+package sandbox
+
+import "syscall"
+
+// OpenBSD has no RLIMIT_AS; RLIMIT_DATA covers the heap that the Go runtime maps.
+const memoryResource = syscall.RLIMIT_DATA
@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd || solaris)
+
+package sandbox
+
+import "errors"
+
+// applyLimits can't limit memory or CPU time on this platform; only the wall time limit, which the parent
+// enforces, applies.
+func applyLimits(l Limits) error {
+	if l.Memory > 0 || l.CPU > 0 {
+		return errors.New("memory and CPU time limits aren't supported on this platform")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd || solaris
+
+package sandbox
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// applyLimits sets the process's resource limits. Going over the CPU time limit ends the process
+// with exitCPU; going over the memory limit makes allocations fail, which the Go runtime treats as fatal.
+func applyLimits(l Limits) error {
+	if l.CPU > 0 {
+		secs := uint64((l.CPU + 999999999) / 1e9)
+		// the soft limit sends SIGXCPU, which is turned into an exit code here; the hard limit is a backstop
+		xcpu := make(chan os.Signal, 1)
+		signal.Notify(xcpu, syscall.SIGXCPU)
+		go func() {
+			<-xcpu
+			os.Exit(exitCPU)
+		}()
+		if err := syscall.Setrlimit(syscall.RLIMIT_CPU, rlimit(secs, secs+1)); err != nil {
+			return err
+		}
+	}
+	if l.Memory > 0 {
+		if err := syscall.Setrlimit(memoryResource, rlimit(uint64(l.Memory), uint64(l.Memory))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
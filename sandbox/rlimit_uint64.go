@@ -0,0 +1,10 @@
+//go:build linux || darwin || netbsd || openbsd || solaris
+
+package sandbox
+
+import "syscall"
+
+// rlimit makes a resource limit.
+func rlimit(cur, max uint64) *syscall.Rlimit {
+	return &syscall.Rlimit{Cur: cur, Max: max}
+}
@@ -0,0 +1,183 @@
+// Package sandbox runs solvers in child processes with limits on their memory, CPU time and wall-clock time,
+// so that a runaway solver fails on its own instead of taking a batch run, or the machine, down with it.
+//
+// The parent uses Run to start a copy of its own executable, giving it the puzzle input on stdin.
+// The child calls Serve, which applies the limits to itself, runs the solver and reports the result on stdout.
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// Limits are the resources a child process may use. A zero limit means no limit.
+type Limits struct {
+	// Memory is in bytes.
+	Memory int64
+	CPU    time.Duration
+	Wall   time.Duration
+}
+
+// exit code of a child that went over its CPU time limit
+const exitCPU = 3
+
+// LimitError reports a child process that went over one of its limits.
+type LimitError struct {
+	// Limit is "memory", "CPU time" or "wall time".
+	Limit string
+	Value string
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("exceeded the %s limit of %s", e.Limit, e.Value)
+}
+
+// CrashError reports a child process that died without reporting a result, e.g. from a panic
+// in a goroutine the solver started.
+type CrashError struct {
+	ExitCode int
+	// Stderr is the end of what the child wrote to stderr.
+	Stderr string
+}
+
+func (e *CrashError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("crashed with exit code %d", e.ExitCode)
+	}
+	return fmt.Sprintf("crashed with exit code %d: %s", e.ExitCode, e.Stderr)
+}
+
+// result is what a child reports on stdout.
+type result struct {
+	Part1       *string      `json:"part1,omitempty"`
+	Part2       *string      `json:"part2,omitempty"`
+	Diagnostics []diag.Entry `json:"diagnostics,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	// Duration is how long the solver itself took.
+	Duration time.Duration `json:"duration"`
+}
+
+// Run runs a program that calls Serve, such as a copy of this executable, giving it input on stdin.
+// Diagnostics the child captured are copied to d.
+// Along with the solution, it returns how long the solver took, not counting the child's startup.
+// Going over a limit is reported as a *LimitError and dying early as a *CrashError.
+func Run(ctx context.Context, name string, args []string, input string, l Limits, d *diag.Sink) (*solutions.Solution, time.Duration, error) {
+	if l.Wall > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.Wall)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = strings.NewReader(input), &stdout, &stderr
+	if err := cmd.Run(); err != nil && cmd.ProcessState == nil {
+		return nil, 0, err
+	}
+
+	switch {
+	case l.Wall > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, 0, &LimitError{"wall time", l.Wall.String()}
+	case cmd.ProcessState.ExitCode() == exitCPU:
+		return nil, 0, &LimitError{"CPU time", l.CPU.String()}
+	case outOfMemory(stderr.String()):
+		return nil, 0, &LimitError{"memory", formatBytes(l.Memory)}
+	}
+
+	var res result
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return nil, 0, &CrashError{ExitCode: cmd.ProcessState.ExitCode(), Stderr: summarize(stderr.String())}
+	}
+	for _, e := range res.Diagnostics {
+		d.Logf(e.Level, "%s", e.Msg)
+	}
+	if res.Error != "" {
+		return nil, res.Duration, errors.New(res.Error)
+	}
+	s := &solutions.Solution{}
+	if res.Part1 != nil {
+		s.Part1 = *res.Part1
+	}
+	if res.Part2 != nil {
+		s.Part2 = *res.Part2
+	}
+	return s, res.Duration, nil
+}
+
+// outOfMemory reports whether a child died for lack of memory, going by what it wrote to stderr.
+// Threads' stacks count against the limit too, so failing to start a thread counts.
+func outOfMemory(stderr string) bool {
+	for _, s := range []string{"out of memory", "cannot allocate memory", "pthread_create failed"} {
+		if strings.Contains(stderr, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarize picks the line that explains why a Go program died out of what it wrote to stderr,
+// or else returns the first line, since a stack dump follows it.
+func summarize(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			return line
+		}
+	}
+	return lines[0]
+}
+
+func formatBytes(n int64) string {
+	const mib = 1 << 20
+	if n%(1<<30) == 0 {
+		return fmt.Sprintf("%dGiB", n>>30)
+	}
+	return fmt.Sprintf("%dMiB", n/mib)
+}
+
+// Serve is the child's side of Run. It applies the limits to the current process, reads the input from stdin,
+// solves it and writes the result to stdout. Diagnostics are captured at the given level and sent along with it.
+func Serve(l Limits, level diag.Level, solve func(input string, d *diag.Sink) (*solutions.Solution, error)) error {
+	if err := applyLimits(l); err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: can't apply limits: %v\n", err)
+	}
+	if l.Memory > 0 {
+		// collect garbage harder near the ceiling, so that only memory that's really in use can break it
+		debug.SetMemoryLimit(l.Memory * 9 / 10)
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	d := diag.New(level)
+	var res result
+	start := time.Now()
+	s, err := solve(string(input), d)
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Error = err.Error()
+	} else {
+		if s.Part1 != nil {
+			a := fmt.Sprint(s.Part1)
+			res.Part1 = &a
+		}
+		if s.Part2 != nil {
+			a := fmt.Sprint(s.Part2)
+			res.Part2 = &a
+		}
+	}
+	res.Diagnostics = d.Entries()
+	return json.NewEncoder(os.Stdout).Encode(&res)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/server"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// runServe serves the web dashboard and its API.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "listen on this `address`")
+	inProcess := fs.Bool("in-process", false, "run solvers in the server's own process instead of a child process each, so that their diagnostics stream in as they run, but a crashing solver takes the server down")
+	fs.Int64Var(&memLimit, "mem-limit", 2048, "memory limit of a run, in `MiB`")
+	fs.DurationVar(&cpuLimit, "cpu-limit", time.Minute, "CPU time limit of a run")
+	fs.DurationVar(&wallLimit, "timeout", 2*time.Minute, "wall-clock time limit of a run")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s serve [flags]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		return 1
+	}
+	s := &server.Server{
+		Registry: solutions.Registry,
+		Input: func(k solutions.Key) (string, error) {
+			return input.Get(k.Year, k.Day)
+		},
+	}
+	if !*inProcess {
+		s.Sandbox = solveSandboxed
+	}
+	fmt.Fprintf(os.Stderr, "Serving on http://%s/\n", *addr)
+	if err := http.ListenAndServe(*addr, s.Handler()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,372 @@
+// Package server serves a JSON API and a small web dashboard for running solvers and browsing their history.
+//
+// Endpoints:
+//
+//	GET  /                   the dashboard
+//	GET  /api/solutions      the registered solvers, with their parameters, implementations and stars
+//	POST /api/run            run a solver and return its answers and diagnostics
+//	GET  /api/run/stream     run a solver, streaming its diagnostics as server-sent events
+//	GET  /api/history        past runs of a solver, and their median runtime by commit
+//
+// The run endpoints take the puzzle as year and day query parameters, plus optional part, impl and
+// repeated param=name=value parameters. Add debug=1 for debug-level diagnostics.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/history"
+	"github.com/jzimbel/adventofcode-go/ledger"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+//go:embed index.html
+var static embed.FS
+
+// Server runs the solvers in a registry on request.
+type Server struct {
+	Registry *solutions.Solvers
+	// Input loads a puzzle's input.
+	Input func(k solutions.Key) (string, error)
+	// Sandbox, if set, runs each solve in place of the server, e.g. in a child process, so that a solver that
+	// panics in a goroutine of its own or runs away with memory fails by itself instead of taking the server
+	// down. It's given the solver's implementation name and its env, and returns how long the solver took.
+	// Without it, solvers run in the server's process, and only panics in the goroutine that calls them are caught.
+	Sandbox func(k solutions.Key, impl string, env *solutions.Env, input string) (*solutions.Solution, time.Duration, error)
+
+	// runs are one at a time, because some solvers keep global caches that aren't safe for concurrent use
+	mu sync.Mutex
+}
+
+// Handler returns the handler for the dashboard and the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/api/solutions", s.list)
+	mux.HandleFunc("/api/run", s.run)
+	mux.HandleFunc("/api/run/stream", s.stream)
+	mux.HandleFunc("/api/history", s.history)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+type param struct {
+	Name    string `json:"name"`
+	Default int    `json:"default"`
+	Usage   string `json:"usage"`
+	// Min and Max are left out for a parameter that takes any value
+	Min *int `json:"min,omitempty"`
+	Max *int `json:"max,omitempty"`
+}
+
+type solution struct {
+	Year      int      `json:"year"`
+	Day       int      `json:"day"`
+	Title     string   `json:"title"`
+	Params    []param  `json:"params"`
+	Impls     []string `json:"impls"`
+	Generator bool     `json:"generator"`
+	Stars     int      `json:"stars"`
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	l, err := ledger.Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	list := []solution{}
+	for _, k := range s.Registry.Keys() {
+		e, _ := s.Registry.Lookup(k.Year, k.Day)
+		sol := solution{Year: k.Year, Day: k.Day, Title: e.Title, Params: []param{}, Impls: e.ImplNames(), Generator: e.Generator != nil}
+		for _, p := range e.Params {
+			sp := param{Name: p.Name, Default: p.Default, Usage: p.Usage}
+			if p.Bounded() {
+				lo, hi := p.Min, p.Max
+				sp.Min, sp.Max = &lo, &hi
+			}
+			sol.Params = append(sol.Params, sp)
+		}
+		if answers, ok := l.Get(k); ok {
+			sol.Stars = answers.Stars()
+		}
+		list = append(list, sol)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// request is a parsed request to run a solver.
+type request struct {
+	entry     solutions.Entry
+	solver    solutions.Solver
+	part      int
+	impl      string
+	overrides map[string]int
+	level     diag.Level
+}
+
+// parseKey reads the puzzle from a request's year and day query parameters.
+func parseKey(r *http.Request) (k solutions.Key, err error) {
+	if k.Year, err = strconv.Atoi(r.FormValue("year")); err != nil {
+		return k, errors.New("year must be an integer")
+	}
+	if k.Day, err = strconv.Atoi(r.FormValue("day")); err != nil {
+		return k, errors.New("day must be an integer")
+	}
+	return k, k.Validate()
+}
+
+func (s *Server) parseRequest(r *http.Request) (*request, error) {
+	k, err := parseKey(r)
+	if err != nil {
+		return nil, err
+	}
+	req := &request{impl: r.FormValue("impl"), overrides: make(map[string]int), level: diag.Info}
+	var ok bool
+	if req.entry, ok = s.Registry.Lookup(k.Year, k.Day); !ok {
+		return nil, fmt.Errorf("no solver registered for %v", k)
+	}
+	if req.solver, ok = req.entry.Impl(req.impl); !ok {
+		return nil, fmt.Errorf("%v has no implementation %q", k, req.impl)
+	}
+	if p := r.FormValue("part"); p != "" {
+		if req.part, err = strconv.Atoi(p); err != nil || req.part < 0 || req.part > 2 {
+			return nil, errors.New("part must be 1, 2, or 0 for both parts")
+		}
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	for _, setting := range r.Form["param"] {
+		name, v, err := solutions.ParseParam(setting)
+		if err != nil {
+			return nil, err
+		}
+		req.overrides[name] = v
+	}
+	if r.FormValue("debug") != "" {
+		req.level = diag.Debug
+	}
+	return req, nil
+}
+
+type diagnostic struct {
+	Level string    `json:"level"`
+	Time  time.Time `json:"time"`
+	Msg   string    `json:"msg"`
+}
+
+func newDiagnostic(e diag.Entry) diagnostic {
+	return diagnostic{e.Level.String(), e.Time, e.Msg}
+}
+
+type result struct {
+	Year  int     `json:"year"`
+	Day   int     `json:"day"`
+	Part  int     `json:"part"`
+	Impl  string  `json:"impl"`
+	Part1 *string `json:"part1"`
+	Part2 *string `json:"part2"`
+	// Duration is in nanoseconds.
+	Duration    time.Duration `json:"duration"`
+	Diagnostics []diagnostic  `json:"diagnostics"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// execute runs a solver on the puzzle input, recording the run in the history unless parameters were overridden.
+func (s *Server) execute(req *request, d *diag.Sink) *result {
+	k := req.entry.Key
+	res := &result{Year: k.Year, Day: k.Day, Part: req.part, Impl: req.impl, Diagnostics: []diagnostic{}}
+	if res.Impl == "" {
+		res.Impl = solutions.DefaultImpl
+	}
+	defer func() {
+		for _, e := range d.Entries() {
+			res.Diagnostics = append(res.Diagnostics, newDiagnostic(e))
+		}
+	}()
+
+	env, err := req.entry.NewEnv(d, req.overrides)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	env.Part = req.part
+	input, err := s.Input(k)
+	if err != nil {
+		res.Error = fmt.Sprintf("failed to load puzzle input: %v", err)
+		return res
+	}
+
+	s.mu.Lock()
+	var sol *solutions.Solution
+	if s.Sandbox != nil {
+		sol, res.Duration, err = s.Sandbox(k, req.impl, env, input)
+	} else {
+		start := time.Now()
+		sol, err = solve(req.solver, input, env)
+		res.Duration = time.Since(start)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	run := history.NewRun(k, req.part, req.impl, sol, res.Duration)
+	res.Part1, res.Part2 = run.Part1, run.Part2
+	if len(req.overrides) == 0 {
+		if err := history.Append(run); err != nil {
+			d.Infof("failed to record run history: %v", err)
+		}
+	}
+	return res
+}
+
+// solve runs a solver, turning a panic into an error so that one bad solver doesn't take the server down.
+// A panic in a goroutine the solver starts can't be caught here; see Server.Sandbox.
+func solve(solver solutions.Solver, input string, env *solutions.Env) (sol *solutions.Solution, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("solver panicked: %v", r)
+		}
+	}()
+	return solver(input, env)
+}
+
+func (s *Server) run(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST to run a solver"))
+		return
+	}
+	req, err := s.parseRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res := s.execute(req, diag.New(req.level))
+	status := http.StatusOK
+	if res.Error != "" {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, res)
+}
+
+// stream runs a solver like run does, but sends each diagnostic message as a "diag" event while the solver runs,
+// and then the result as a "result" event. A sandboxed solver's messages only arrive once it's done. It's a GET so that browsers' EventSource can use it.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming isn't supported"))
+		return
+	}
+	req, err := s.parseRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// messages are queued rather than sent on a channel so that a slow or departed client never blocks the solver
+	var mu sync.Mutex
+	var queue []diag.Entry
+	wake := make(chan struct{}, 1)
+	d := diag.New(req.level)
+	d.Notify(func(e diag.Entry) {
+		mu.Lock()
+		queue = append(queue, e)
+		mu.Unlock()
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	})
+	done := make(chan *result, 1)
+	go func() {
+		done <- s.execute(req, d)
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(event string, v interface{}) {
+		b, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	}
+	flush := func() {
+		mu.Lock()
+		pending := queue
+		queue = nil
+		mu.Unlock()
+		for _, e := range pending {
+			send("diag", newDiagnostic(e))
+		}
+		flusher.Flush()
+	}
+	for {
+		select {
+		case <-wake:
+			flush()
+		case res := <-done:
+			flush()
+			// the messages were already streamed
+			res.Diagnostics = nil
+			send("result", res)
+			flusher.Flush()
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) history(w http.ResponseWriter, r *http.Request) {
+	k, err := parseKey(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	f := history.Filter{Key: k, Impl: r.FormValue("impl"), Machine: r.FormValue("machine")}
+	if p := r.FormValue("part"); p != "" {
+		if f.Part, err = strconv.Atoi(p); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("part must be an integer"))
+			return
+		}
+	}
+	runs, err := history.Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	matched := []history.Run{}
+	for i := range runs {
+		if f.Match(&runs[i]) {
+			matched = append(matched, runs[i])
+		}
+	}
+	points := history.ByCommit(runs, f)
+	if points == nil {
+		points = []history.Point{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Runs   []history.Run   `json:"runs"`
+		Points []history.Point `json:"points"`
+	}{matched, points})
+}
@@ -0,0 +1,267 @@
+// Command gen writes the files that register solution packages in the solver registry.
+//
+// It scans solutions/y*/d*/ for packages with an exported Solve function and writes
+// an init.go for each year package as well as solutioninit/init.go, which imports the years.
+// If a day package also declares a Title constant, a Params variable or a Generate function, they're registered along with the solver,
+// and so is any function named like SolveSomething, as an alternative implementation called "something".
+// Run it with `go generate ./solutioninit`. With -check, nothing is written and gen exits
+// with a nonzero status if any generated file is missing or stale.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const header = "// Code generated by solutioninit/gen; DO NOT EDIT.\n\n"
+
+var (
+	yearPattern = regexp.MustCompile(`^y(\d{4})$`)
+	dayPattern  = regexp.MustCompile(`^d(\d{2})$`)
+	implPattern = regexp.MustCompile(`^Solve([A-Z]\w*)$`)
+)
+
+type day struct {
+	Pkg       string
+	Day       int
+	Title     bool
+	Params    bool
+	Generator bool
+	Impls     []impl
+}
+
+type impl struct {
+	Name string
+	Func string
+}
+
+type year struct {
+	Pkg  string
+	Year int
+	Days []day
+}
+
+var yearTemplate = template.Must(template.New("year").Parse(header + `package {{.Year.Pkg}}
+
+import (
+	"{{.Module}}/solutions"
+{{- range .Year.Days}}
+	"{{$.Module}}/solutions/{{$.Year.Pkg}}/{{.Pkg}}"
+{{- end}}
+)
+
+func init() {
+	r, y := solutions.Registry, {{.Year.Year}}
+{{- range $day := .Year.Days}}
+	r.Register(y, {{.Day}}, {{.Pkg}}.Solve
+		{{- if .Title}}, solutions.WithTitle({{.Pkg}}.Title){{end}}
+		{{- if .Params}}, solutions.WithParams({{.Pkg}}.Params...){{end}}
+		{{- if .Generator}}, solutions.WithGenerator({{.Pkg}}.Generate){{end}}
+		{{- range .Impls}}, solutions.WithImpl("{{.Name}}", {{$day.Pkg}}.{{.Func}}){{end}})
+{{- end}}
+}
+`))
+
+var initTemplate = template.Must(template.New("init").Parse(header + `// Package solutioninit imports all solution packages
+// so that they can register themselves in the solution registry defined in registry.go.
+package solutioninit
+
+//go:generate go run ./gen
+
+import (
+{{- range .Years}}
+	// register solutions for {{.Year}} puzzles
+	_ "{{$.Module}}/solutions/{{.Pkg}}"
+{{- end}}
+)
+`))
+
+// exports returns the names of the top-level functions, and of the variables and constants, declared by the Go package in dir.
+func exports(dir string) (funcs map[string]bool, values map[string]bool, err error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, err
+	}
+	funcs, values = make(map[string]bool), make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				switch d := decl.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil {
+						funcs[d.Name.Name] = true
+					}
+				case *ast.GenDecl:
+					if d.Tok != token.VAR && d.Tok != token.CONST {
+						continue
+					}
+					for _, spec := range d.Specs {
+						for _, name := range spec.(*ast.ValueSpec).Names {
+							values[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// impls finds alternative solver implementations among a package's functions, ordered by name.
+func impls(funcs map[string]bool) (found []impl) {
+	for name := range funcs {
+		if m := implPattern.FindStringSubmatch(name); m != nil {
+			found = append(found, impl{Name: strings.ToLower(m[1]), Func: name})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return
+}
+
+// subdirs returns the numbers captured by pattern in the names of dir's subdirectories, keyed by name.
+func subdirs(dir string, pattern *regexp.Regexp) (map[string]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]int)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if m := pattern.FindStringSubmatch(e.Name()); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			found[e.Name()] = n
+		}
+	}
+	return found, nil
+}
+
+// scan finds all years and days with solutions under root/solutions.
+func scan(root string) ([]year, error) {
+	solutionsDir := filepath.Join(root, "solutions")
+	yearDirs, err := subdirs(solutionsDir, yearPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var years []year
+	for yearPkg, y := range yearDirs {
+		dayDirs, err := subdirs(filepath.Join(solutionsDir, yearPkg), dayPattern)
+		if err != nil {
+			return nil, err
+		}
+		yr := year{Pkg: yearPkg, Year: y}
+		for dayPkg, d := range dayDirs {
+			funcs, values, err := exports(filepath.Join(solutionsDir, yearPkg, dayPkg))
+			if err != nil {
+				return nil, err
+			}
+			if funcs["Solve"] {
+				yr.Days = append(yr.Days, day{
+					Pkg:       dayPkg,
+					Day:       d,
+					Title:     values["Title"],
+					Params:    values["Params"],
+					Generator: funcs["Generate"],
+					Impls:     impls(funcs),
+				})
+			}
+		}
+		if len(yr.Days) == 0 {
+			continue
+		}
+		sort.Slice(yr.Days, func(i, j int) bool { return yr.Days[i].Day < yr.Days[j].Day })
+		years = append(years, yr)
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+	return years, nil
+}
+
+func render(t *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// generate returns the contents of every generated file, keyed by path.
+func generate(root, module string) (map[string][]byte, error) {
+	years, err := scan(root)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(years)+1)
+	for _, y := range years {
+		src, err := render(yearTemplate, struct {
+			Module string
+			Year   year
+		}{module, y})
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s: %w", y.Pkg, err)
+		}
+		files[filepath.Join(root, "solutions", y.Pkg, "init.go")] = src
+	}
+	src, err := render(initTemplate, struct {
+		Module string
+		Years  []year
+	}{module, years})
+	if err != nil {
+		return nil, fmt.Errorf("rendering solutioninit: %w", err)
+	}
+	files[filepath.Join(root, "solutioninit", "init.go")] = src
+	return files, nil
+}
+
+func main() {
+	root := flag.String("root", "..", "path to the repository root")
+	module := flag.String("module", "github.com/jzimbel/adventofcode-go", "import path of the repository root")
+	check := flag.Bool("check", false, "report stale generated files instead of writing them")
+	flag.Parse()
+
+	files, err := generate(*root, *module)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var stale bool
+	for _, path := range paths {
+		if *check {
+			if existing, err := os.ReadFile(path); err != nil || !bytes.Equal(existing, files[path]) {
+				fmt.Fprintf(os.Stderr, "gen: %s is stale; run go generate ./solutioninit\n", path)
+				stale = true
+			}
+			continue
+		}
+		if err := os.WriteFile(path, files[path], 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "gen:", err)
+			os.Exit(1)
+		}
+	}
+	if stale {
+		os.Exit(1)
+	}
+}
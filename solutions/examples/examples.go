@@ -0,0 +1,172 @@
+// Package examples loads the example inputs stored alongside solutions and checks solvers against them.
+//
+// Examples for a day live in an examples directory in the day's package, e.g. solutions/y2019/d12/examples.
+// Each example is a pair of files: NAME.txt holds the input and NAME.json holds what's known about it:
+//
+//	{"part1": 179, "part2": "2772", "params": {"steps": 10}}
+//
+// Answers are compared to the solver's after formatting both with fmt.Sprint, and either can be left out.
+// Params override the solver's parameter defaults for that example only.
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// Example is a puzzle input with known answers.
+type Example struct {
+	Name  string
+	Input string
+	// Part1 and Part2 are the expected answers, or nil if they aren't known.
+	Part1 *string
+	Part2 *string
+	// Params are parameter overrides to run the example with.
+	Params map[string]int
+}
+
+// spec is the format of an example's JSON file.
+type spec struct {
+	Part1  interface{}    `json:"part1,omitempty"`
+	Part2  interface{}    `json:"part2,omitempty"`
+	Params map[string]int `json:"params,omitempty"`
+}
+
+// MismatchError reports an answer that differs from the expected one.
+type MismatchError struct {
+	Example string
+	Part    int
+	Got     string
+	Want    string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("example %s part %d: got %q, want %q", e.Example, e.Part, e.Got, e.Want)
+}
+
+var integerPattern = regexp.MustCompile(`^-?\d+$`)
+
+// Root returns the path of the repository root, found relative to this source file.
+func Root() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
+// Dir returns the directory that holds examples for a puzzle.
+func Dir(k solutions.Key) string {
+	return filepath.Join(Root(), "solutions", fmt.Sprintf("y%d", k.Year), fmt.Sprintf("d%02d", k.Day), "examples")
+}
+
+func expected(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	s := fmt.Sprint(v)
+	return &s
+}
+
+// stored is the inverse of expected, keeping integer answers as JSON numbers.
+func stored(s *string) interface{} {
+	switch {
+	case s == nil:
+		return nil
+	case integerPattern.MatchString(*s):
+		return json.Number(*s)
+	default:
+		return *s
+	}
+}
+
+// Load reads all examples for a puzzle, ordered by name. A puzzle without examples has none, and that's not an error.
+func Load(k solutions.Key) ([]Example, error) {
+	dir := Dir(k)
+	inputs, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(inputs)
+
+	exs := make([]Example, 0, len(inputs))
+	for _, path := range inputs {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ex := Example{Name: name, Input: strings.TrimRight(string(input), "\n")}
+
+		raw, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			var sp spec
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&sp); err != nil {
+				return nil, fmt.Errorf("parsing %s.json: %w", filepath.Join(dir, name), err)
+			}
+			ex.Part1, ex.Part2, ex.Params = expected(sp.Part1), expected(sp.Part2), sp.Params
+		}
+		exs = append(exs, ex)
+	}
+	return exs, nil
+}
+
+// Check compares a solution to the example's expected answers.
+func (ex *Example) Check(s *solutions.Solution) error {
+	for i, part := range [...]struct {
+		got  interface{}
+		want *string
+	}{{s.Part1, ex.Part1}, {s.Part2, ex.Part2}} {
+		if part.want == nil {
+			continue
+		}
+		if got := fmt.Sprint(part.got); got != *part.want {
+			return &MismatchError{Example: ex.Name, Part: i + 1, Got: got, Want: *part.want}
+		}
+	}
+	return nil
+}
+
+// Run solves the example with the entry's solver and checks the answers.
+func (ex *Example) Run(e solutions.Entry, d *diag.Sink) (*solutions.Solution, error) {
+	env, err := e.NewEnv(d, ex.Params)
+	if err != nil {
+		return nil, fmt.Errorf("example %s: %w", ex.Name, err)
+	}
+	s, err := e.Solver(ex.Input, env)
+	if err != nil {
+		return nil, fmt.Errorf("example %s: %w", ex.Name, err)
+	}
+	return s, ex.Check(s)
+}
+
+// Save writes an example to the puzzle's examples directory, replacing any example with the same name.
+func Save(k solutions.Key, ex *Example) error {
+	dir := Dir(k)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	sp := spec{Part1: stored(ex.Part1), Part2: stored(ex.Part2), Params: ex.Params}
+	raw, err := json.Marshal(sp)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ex.Name+".txt"), []byte(ex.Input+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ex.Name+".json"), append(raw, '\n'), 0644)
+}
@@ -0,0 +1,183 @@
+// Package external provides solvers that run as separate executables, so solutions can be written in any language.
+//
+// An external solver receives the puzzle input on stdin and prints a JSON object with the answers to stdout:
+//
+//	{"part1": 3394106, "part2": "some text"}
+//
+// Either part may be omitted. When the caller only wants one part, the AOC_PART environment variable is set to
+// 1 or 2, and the solver can skip the other; it's 0 when both are wanted. A solver can report failure by exiting with a nonzero status
+// or by printing an object with an "error" field. Anything written to stderr is captured
+// and reported alongside errors, or as diagnostics if the solver succeeds.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// DefaultTimeout is used for solvers whose config doesn't specify a timeout.
+const DefaultTimeout = time.Minute
+
+// ConfigEnvVar names an environment variable that overrides the config file path.
+const ConfigEnvVar = "AOC_EXTERNAL_SOLVERS"
+
+// PartEnvVar names the environment variable that tells a solver which part is wanted, or 0 for both.
+const PartEnvVar = "AOC_PART"
+
+// waitDelay is how long a solver's output can stay open after it exits or is killed, such as by a process it
+// started that's still running. After that the output is closed and the solver fails.
+const waitDelay = time.Second
+
+// Spec describes an external solver in the config file.
+type Spec struct {
+	Year int `json:"year"`
+	Day  int `json:"day"`
+	// Title is the puzzle's name. It's optional.
+	Title string `json:"title,omitempty"`
+	// Command is the executable followed by its arguments.
+	Command []string `json:"command"`
+	// Dir is the working directory of the command. Relative paths are resolved against the config file's directory.
+	Dir string `json:"dir,omitempty"`
+	// Timeout is a duration string like "30s". Defaults to DefaultTimeout.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Config is the format of the external solver config file.
+type Config struct {
+	Solvers []Spec `json:"solvers"`
+}
+
+// output is what an external solver prints to stdout.
+type output struct {
+	Part1 interface{} `json:"part1"`
+	Part2 interface{} `json:"part2"`
+	Error string      `json:"error"`
+}
+
+// DefaultConfigPath returns the path of the config file to use when none is given explicitly.
+func DefaultConfigPath() (string, error) {
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "adventofcode-go", "external.json"), nil
+}
+
+// LoadConfig reads a config file. A missing file is not an error; it just has no solvers.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var c Config
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	base := filepath.Dir(path)
+	for i := range c.Solvers {
+		if c.Solvers[i].Dir == "" {
+			c.Solvers[i].Dir = base
+		} else if !filepath.IsAbs(c.Solvers[i].Dir) {
+			c.Solvers[i].Dir = filepath.Join(base, c.Solvers[i].Dir)
+		}
+	}
+	return &c, nil
+}
+
+// Solver returns a solver that runs the executable described by s.
+func (s *Spec) Solver() (solutions.Solver, error) {
+	if len(s.Command) == 0 {
+		return nil, fmt.Errorf("external solver for %v has no command", solutions.Key{Year: s.Year, Day: s.Day})
+	}
+	timeout := DefaultTimeout
+	if s.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(s.Timeout); err != nil {
+			return nil, fmt.Errorf("external solver for %v: %w", solutions.Key{Year: s.Year, Day: s.Day}, err)
+		}
+	}
+	command, dir := append([]string(nil), s.Command...), s.Dir
+
+	return func(input string, env *solutions.Env) (*solutions.Solution, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", PartEnvVar, env.Part))
+		cmd.WaitDelay = waitDelay
+		cmd.Stdin = strings.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		err := cmd.Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, withStderr(fmt.Errorf("%s timed out after %v", command[0], timeout), &stderr)
+		}
+		if errors.Is(err, exec.ErrWaitDelay) {
+			return nil, withStderr(fmt.Errorf("%s exited but its output was still open after %v", command[0], waitDelay), &stderr)
+		}
+		if err != nil {
+			return nil, withStderr(fmt.Errorf("%s: %w", command[0], err), &stderr)
+		}
+
+		var out output
+		dec := json.NewDecoder(&stdout)
+		// keep numbers exactly as printed instead of converting them to float64
+		dec.UseNumber()
+		if err := dec.Decode(&out); err != nil {
+			return nil, withStderr(fmt.Errorf("%s printed invalid output: %w", command[0], err), &stderr)
+		}
+		if out.Error != "" {
+			return nil, withStderr(fmt.Errorf("%s: %s", command[0], out.Error), &stderr)
+		}
+		if stderr.Len() > 0 {
+			env.Diag.Writer(diag.Info).Write(stderr.Bytes())
+		}
+		return &solutions.Solution{Part1: out.Part1, Part2: out.Part2}, nil
+	}, nil
+}
+
+// withStderr adds captured stderr output, if there is any, to an error message.
+func withStderr(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%w\nstderr:\n%s", err, msg)
+	}
+	return err
+}
+
+// Register adds solvers for every spec in c to r.
+func (c *Config) Register(r *solutions.Solvers) error {
+	for i := range c.Solvers {
+		s, err := c.Solvers[i].Solver()
+		if err != nil {
+			return err
+		}
+		if err := r.Add(c.Solvers[i].Year, c.Solvers[i].Day, s, solutions.WithTitle(c.Solvers[i].Title)); err != nil {
+			return fmt.Errorf("registering external solver: %w", err)
+		}
+	}
+	return nil
+}
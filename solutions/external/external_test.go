@@ -0,0 +1,62 @@
+package external
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+func TestSolver(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to run solvers with")
+	}
+	tests := []struct {
+		name    string
+		script  string
+		timeout string
+		part    int
+		want    [2]string
+		wantErr string
+	}{
+		{"answers", `echo '{"part1": 12, "part2": "abc"}'`, "", 0, [2]string{"12", "abc"}, ""},
+		{"input on stdin", `read line; echo "{\"part1\": \"$line\"}"`, "", 0, [2]string{"hi", "<nil>"}, ""},
+		{"both parts wanted", `echo "{\"part1\": $AOC_PART}"`, "", 0, [2]string{"0", "<nil>"}, ""},
+		{"one part wanted", `echo "{\"part2\": $AOC_PART}"`, "", 2, [2]string{"<nil>", "2"}, ""},
+		{"error field", `echo '{"error": "no answer"}'`, "", 0, [2]string{}, "no answer"},
+		{"nonzero exit", `echo oops >&2; exit 3`, "", 0, [2]string{}, "oops"},
+		{"invalid output", `echo nope`, "", 0, [2]string{}, "invalid output"},
+		{"timeout", `sleep 5; echo '{}'`, "100ms", 0, [2]string{}, "timed out"},
+		// the background sleep keeps stdout open after the solver exits
+		{"output left open", `sleep 5 & echo '{}'`, "", 0, [2]string{}, "still open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := Spec{Year: 2019, Day: 1, Command: []string{"sh", "-c", tt.script}, Timeout: tt.timeout}
+			solver, err := spec.Solver()
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			s, err := solver("hi\n", &solutions.Env{Part: tt.part})
+			if d := time.Since(start); d > 4*time.Second {
+				t.Errorf("solver took %v", d)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := [2]string{fmt.Sprint(s.Part1), fmt.Sprint(s.Part2)}; got != tt.want {
+				t.Errorf("got answers %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
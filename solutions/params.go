@@ -0,0 +1,119 @@
+package solutions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+)
+
+// Param is a tunable value that a solver declares, usually a puzzle constant that examples use a different value for.
+type Param struct {
+	Name    string
+	Default int
+	Usage   string
+	// Min and Max are the smallest and largest values the solver can handle. If both are 0, it takes any value.
+	Min, Max int
+}
+
+// Bounded reports whether the parameter has a Min and Max.
+func (p *Param) Bounded() bool {
+	return p.Min != 0 || p.Max != 0
+}
+
+// check returns an error if v is out of the parameter's bounds.
+func (p *Param) check(v int) error {
+	if p.Bounded() && (v < p.Min || v > p.Max) {
+		return fmt.Errorf("%w: %s=%d is outside of [%d,%d]", ErrParamRange, p.Name, v, p.Min, p.Max)
+	}
+	return nil
+}
+
+// Errors returned when resolving parameters.
+var (
+	ErrUnknownParam = errors.New("unknown parameter")
+	ErrParamRange   = errors.New("parameter out of range")
+)
+
+func validateParams(params []Param) error {
+	seen := make(map[string]bool, len(params))
+	for _, p := range params {
+		if p.Name == "" || strings.ContainsAny(p.Name, "= ") {
+			return fmt.Errorf("invalid parameter name %q", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("parameter %q declared twice", p.Name)
+		}
+		if p.Min > p.Max {
+			return fmt.Errorf("parameter %q has Min %d above Max %d", p.Name, p.Min, p.Max)
+		}
+		if err := p.check(p.Default); err != nil {
+			return fmt.Errorf("bad default: %w", err)
+		}
+		seen[p.Name] = true
+	}
+	return nil
+}
+
+// ParseParam parses a parameter setting of the form name=value.
+func ParseParam(s string) (name string, value int, err error) {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return "", 0, fmt.Errorf("parameter %q should look like name=value", s)
+	}
+	name = s[:i]
+	if value, err = strconv.Atoi(s[i+1:]); err != nil {
+		return "", 0, fmt.Errorf("parameter %s must be an integer: %w", name, err)
+	}
+	return
+}
+
+// ResolveParams returns the value of every declared parameter: its override if there is one, otherwise its default.
+// Overriding a parameter that wasn't declared, or with a value outside of its bounds, is an error.
+func ResolveParams(declared []Param, overrides map[string]int) (map[string]int, error) {
+	values := make(map[string]int, len(declared))
+	for _, p := range declared {
+		values[p.Name] = p.Default
+	}
+
+	var unknown []string
+	for name, v := range overrides {
+		if _, ok := values[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		values[name] = v
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownParam, strings.Join(unknown, ", "))
+	}
+	for i := range declared {
+		if err := declared[i].check(values[declared[i].Name]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// NewEnv returns an environment for running the entry's solver with the given parameter overrides.
+func (e Entry) NewEnv(d *diag.Sink, overrides map[string]int) (*Env, error) {
+	params, err := ResolveParams(e.Params, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", e.Key, err)
+	}
+	return &Env{Diag: d, Params: params}, nil
+}
+
+// Param returns the value of a parameter the solver declared.
+// Asking for an undeclared parameter is a bug in the solver, so it panics.
+func (e *Env) Param(name string) int {
+	v, ok := e.Params[name]
+	if !ok {
+		panic(fmt.Sprintf("solver read undeclared parameter %q", name))
+	}
+	return v
+}
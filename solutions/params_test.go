@@ -0,0 +1,62 @@
+package solutions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveParams(t *testing.T) {
+	declared := []Param{
+		{Name: "steps", Default: 10},
+		{Name: "width", Default: 25, Min: 1, Max: 100},
+		{Name: "offset", Default: 0, Min: -5, Max: 5},
+	}
+	tests := []struct {
+		name      string
+		overrides map[string]int
+		want      map[string]int
+		wantErr   error
+	}{
+		{"defaults", nil, map[string]int{"steps": 10, "width": 25, "offset": 0}, nil},
+		{"overrides", map[string]int{"steps": -3, "width": 100, "offset": -5}, map[string]int{"steps": -3, "width": 100, "offset": -5}, nil},
+		{"unknown", map[string]int{"height": 6}, nil, ErrUnknownParam},
+		{"zero", map[string]int{"width": 0}, nil, ErrParamRange},
+		{"negative", map[string]int{"width": -1}, nil, ErrParamRange},
+		{"too big", map[string]int{"width": 101}, nil, ErrParamRange},
+		{"below a negative min", map[string]int{"offset": -6}, nil, ErrParamRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveParams(declared, tt.overrides)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		param Param
+		ok    bool
+	}{
+		{"unbounded", Param{Name: "steps", Default: -1}, true},
+		{"bounded", Param{Name: "width", Default: 25, Min: 1, Max: 100}, true},
+		{"no name", Param{Default: 1}, false},
+		{"name with =", Param{Name: "a=b"}, false},
+		{"min above max", Param{Name: "width", Default: 5, Min: 10, Max: 1}, false},
+		{"default out of range", Param{Name: "width", Default: 0, Min: 1, Max: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateParams([]Param{tt.param}); (err == nil) != tt.ok {
+				t.Errorf("got error %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
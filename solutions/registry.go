@@ -1,7 +1,13 @@
 package solutions
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/jzimbel/adventofcode-go/diag"
 )
 
 // Solution for parts 1 and 2 of a daily puzzle.
@@ -10,29 +16,233 @@ type Solution struct {
 	Part2 interface{}
 }
 
-// Solver is a puzzle solver function type. Takes a puzzle input and returns a solution struct or an error.
-type Solver func(string) (*Solution, error)
+// Env holds what a solver gets from its caller besides the puzzle input.
+type Env struct {
+	// Diag receives diagnostic output that isn't part of the answers.
+	Diag *diag.Sink
+	// Params holds a value for each parameter the solver declared.
+	Params map[string]int
+	// Part is the only part the caller wants answered, or 0 for both.
+	// Solvers should leave out the answer to a part that Wants reports isn't wanted, and skip its work where
+	// they can, so that benchmarking one part doesn't time both; solutiontest checks that they do.
+	Part int
+}
+
+// Wants reports whether the caller wants the answer to a part.
+func (e *Env) Wants(part int) bool {
+	return e.Part == 0 || e.Part == part
+}
+
+// Solver is a puzzle solver function type. Takes a puzzle input and an environment, and returns a solution struct or an error.
+// The environment is never nil.
+type Solver func(string, *Env) (*Solution, error)
+
+// Generator produces a random, valid puzzle input for testing solvers offline.
+// The same random source state always produces the same input.
+// Size sets the scale of the input, like a number of lines; each generator documents what it means.
+// A size of 0 makes an input about as big as a real one.
+type Generator func(rng *rand.Rand, size int) string
+
+// Bounds on the years and days that Advent of Code puzzles exist for.
+const (
+	FirstYear = 2015
+	FirstDay  = 1
+	LastDay   = 25
+)
+
+// Errors returned when registering solvers.
+var (
+	ErrInvalidKey = errors.New("no such puzzle")
+	ErrDuplicate  = errors.New("solver already registered")
+	ErrNilSolver  = errors.New("solver is nil")
+)
+
+// Key identifies a daily puzzle.
+type Key struct {
+	Year int
+	Day  int
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%d-%02d", k.Year, k.Day)
+}
+
+// Validate returns an error if there can't be a puzzle for the key's year and day.
+func (k Key) Validate() error {
+	if k.Year < FirstYear {
+		return fmt.Errorf("%w: year %d is before %d", ErrInvalidKey, k.Year, FirstYear)
+	}
+	if k.Day < FirstDay || k.Day > LastDay {
+		return fmt.Errorf("%w: day %d is outside of [%d,%d]", ErrInvalidKey, k.Day, FirstDay, LastDay)
+	}
+	return nil
+}
+
+// Entry is everything the registry knows about a puzzle's solver.
+type Entry struct {
+	Key    Key
+	Solver Solver
+	// Title is the puzzle's name, or empty if it isn't known.
+	Title string
+	// Params are the tunable parameters the solver reads from its Env.
+	Params []Param
+	// Generator makes synthetic inputs for the puzzle. It's nil if the puzzle doesn't have one.
+	Generator Generator
+	// Impls are alternative implementations of Solver, keyed by name, that should give the same answers.
+	Impls map[string]Solver
+}
+
+// DefaultImpl is the name of an entry's main Solver among its implementations.
+const DefaultImpl = "default"
+
+// Impl returns the implementation with the given name. An empty name means DefaultImpl.
+func (e Entry) Impl(name string) (s Solver, ok bool) {
+	if name == "" || name == DefaultImpl {
+		return e.Solver, true
+	}
+	s, ok = e.Impls[name]
+	return
+}
+
+// ImplNames returns the names of all of the entry's implementations, starting with DefaultImpl.
+func (e Entry) ImplNames() []string {
+	names := make([]string, 0, len(e.Impls)+1)
+	for name := range e.Impls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultImpl}, names...)
+}
+
+// Option sets optional information about a solver when it's registered.
+type Option func(*Entry)
+
+// WithParams declares the parameters a solver reads from its Env.
+func WithParams(params ...Param) Option {
+	return func(e *Entry) {
+		e.Params = append(e.Params, params...)
+	}
+}
+
+// WithTitle sets the puzzle's name.
+func WithTitle(title string) Option {
+	return func(e *Entry) {
+		e.Title = title
+	}
+}
+
+// WithGenerator sets the function that makes synthetic inputs for a puzzle.
+func WithGenerator(g Generator) Option {
+	return func(e *Entry) {
+		e.Generator = g
+	}
+}
+
+// WithImpl adds an alternative implementation of a solver, like a brute-force version of an optimized one.
+func WithImpl(name string, s Solver) Option {
+	return func(e *Entry) {
+		if e.Impls == nil {
+			e.Impls = make(map[string]Solver)
+		}
+		e.Impls[name] = s
+	}
+}
+
+// Solvers holds the solvers for each puzzle. It's safe for concurrent use.
+// Instantiate with NewSolvers.
+type Solvers struct {
+	mu      sync.RWMutex
+	entries map[Key]*Entry
+}
 
-type registry map[string]Solver
+// NewSolvers returns an empty set of solvers.
+// Tests can use this to build registries isolated from Registry.
+func NewSolvers() *Solvers {
+	return &Solvers{entries: make(map[Key]*Entry)}
+}
+
+// Add adds a new solver to the registry.
+// It fails if the year and day are out of range or already have a solver.
+func (r *Solvers) Add(year int, day int, f Solver, opts ...Option) error {
+	k := Key{year, day}
+	if err := k.Validate(); err != nil {
+		return err
+	}
+	if f == nil {
+		return fmt.Errorf("%w: %v", ErrNilSolver, k)
+	}
+	e := &Entry{Key: k, Solver: f}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if err := validateParams(e.Params); err != nil {
+		return fmt.Errorf("%v: %w", k, err)
+	}
+	for name, s := range e.Impls {
+		if name == "" || name == DefaultImpl || s == nil {
+			return fmt.Errorf("%v: invalid implementation %q", k, name)
+		}
+	}
 
-func getKey(year int, day int) string {
-	return fmt.Sprintf("%d-%02d", year, day)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[k]; ok {
+		return fmt.Errorf("%w: %v", ErrDuplicate, k)
+	}
+	r.entries[k] = e
+	return nil
 }
 
-// Register adds a new solver to the solution registry.
-func (r registry) Register(year int, day int, f Solver) {
-	r[getKey(year, day)] = f
+// Register is like Add, but panics on failure. Meant to be called from init functions.
+func (r *Solvers) Register(year int, day int, f Solver, opts ...Option) {
+	if err := r.Add(year, day, f, opts...); err != nil {
+		panic(err)
+	}
 }
 
 // Get looks up a solver in the registry and returns it if found, as well as a bool indicating success/failure.
-func (r registry) Get(year int, day int) (s Solver, ok bool) {
-	s, ok = r[getKey(year, day)]
+func (r *Solvers) Get(year int, day int) (s Solver, ok bool) {
+	e, ok := r.Lookup(year, day)
+	return e.Solver, ok
+}
+
+// Lookup returns a copy of the registry entry for a puzzle if there is one.
+// Its Params and Impls are copies too, so changing them doesn't change the registry.
+func (r *Solvers) Lookup(year int, day int) (e Entry, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pe, ok := r.entries[Key{year, day}]
+	if !ok {
+		return
+	}
+	e = *pe
+	e.Params = append([]Param(nil), pe.Params...)
+	if pe.Impls != nil {
+		e.Impls = make(map[string]Solver, len(pe.Impls))
+		for name, s := range pe.Impls {
+			e.Impls[name] = s
+		}
+	}
 	return
 }
 
-// Registry of solver functions.
-var Registry registry
+// Keys returns the keys of all registered solvers, ordered by year and then day.
+func (r *Solvers) Keys() []Key {
+	r.mu.RLock()
+	keys := make([]Key, 0, len(r.entries))
+	for k := range r.entries {
+		keys = append(keys, k)
+	}
+	r.mu.RUnlock()
 
-func init() {
-	Registry = make(map[string]Solver)
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Year != keys[j].Year {
+			return keys[i].Year < keys[j].Year
+		}
+		return keys[i].Day < keys[j].Day
+	})
+	return keys
 }
+
+// Registry is the default set of solver functions. Solution packages register themselves here.
+var Registry = NewSolvers()
@@ -0,0 +1,106 @@
+package solutions
+
+import (
+	"errors"
+	"testing"
+)
+
+func solve(string, *Env) (*Solution, error) {
+	return &Solution{}, nil
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name      string
+		year, day int
+		f         Solver
+		want      error
+	}{
+		{"first day", 2019, 1, solve, nil},
+		{"last day", 2019, 25, solve, nil},
+		{"day 0", 2019, 0, solve, ErrInvalidKey},
+		{"day 26", 2019, 26, solve, ErrInvalidKey},
+		{"negative day", 2019, -1, solve, ErrInvalidKey},
+		{"year before the first", FirstYear - 1, 1, solve, ErrInvalidKey},
+		{"nil solver", 2019, 2, nil, ErrNilSolver},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSolvers()
+			err := r.Add(tt.year, tt.day, tt.f)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+			if _, ok := r.Lookup(tt.year, tt.day); ok != (tt.want == nil) {
+				t.Errorf("Lookup found a solver: %v, want %v", ok, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestAddDuplicate(t *testing.T) {
+	r := NewSolvers()
+	if err := r.Add(2019, 1, solve, WithTitle("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(2019, 1, solve, WithTitle("second")); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("got error %v, want %v", err, ErrDuplicate)
+	}
+	if e, _ := r.Lookup(2019, 1); e.Title != "first" {
+		t.Errorf("duplicate replaced the first solver: title is %q", e.Title)
+	}
+	if err := r.Add(2019, 2, solve); err != nil {
+		t.Errorf("another day of the same year: %v", err)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	r := NewSolvers()
+	r.Register(2019, 1, solve)
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate didn't panic")
+		}
+	}()
+	r.Register(2019, 1, solve)
+}
+
+func TestLookupCopies(t *testing.T) {
+	r := NewSolvers()
+	r.Register(2019, 1, solve, WithParams(Param{Name: "size", Default: 5}), WithImpl("slow", solve))
+
+	e, _ := r.Lookup(2019, 1)
+	e.Title = "changed"
+	e.Params[0].Default = 10
+	e.Params = append(e.Params, Param{Name: "extra"})
+	e.Impls["fast"] = solve
+	delete(e.Impls, "slow")
+
+	e, _ = r.Lookup(2019, 1)
+	if e.Title != "" {
+		t.Errorf("title is %q, want it empty", e.Title)
+	}
+	if len(e.Params) != 1 || e.Params[0].Default != 5 {
+		t.Errorf("params are %+v, want only size with default 5", e.Params)
+	}
+	if _, ok := e.Impls["slow"]; !ok || len(e.Impls) != 1 {
+		t.Errorf("implementations are %v, want only slow", e.ImplNames())
+	}
+}
+
+func TestKeysOrder(t *testing.T) {
+	r := NewSolvers()
+	for _, k := range []Key{{2020, 1}, {2019, 25}, {2019, 3}} {
+		r.Register(k.Year, k.Day, solve)
+	}
+	want := []Key{{2019, 3}, {2019, 25}, {2020, 1}}
+	got := r.Keys()
+	if len(got) != len(want) {
+		t.Fatalf("got keys %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got keys %v, want %v", got, want)
+		}
+	}
+}
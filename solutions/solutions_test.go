@@ -0,0 +1,60 @@
+package solutions_test
+
+import (
+	"errors"
+	"testing"
+
+	_ "github.com/jzimbel/adventofcode-go/solutioninit"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/solutiontest"
+)
+
+// TestCrossCheck checks that the registered solvers' alternative implementations agree with their default ones
+// on generated inputs.
+func TestCrossCheck(t *testing.T) {
+	solutiontest.CrossCheck(t, solutions.Registry, solutiontest.CrossCheckRuns)
+}
+
+// TestParamBounds checks that the registered solvers reject parameter values they can't handle,
+// rather than crashing on them.
+func TestParamBounds(t *testing.T) {
+	tests := []struct {
+		year, day int
+		param     string
+		value     int
+	}{
+		{2019, 7, "amps", 0},
+		{2019, 7, "amps", -1},
+		{2019, 7, "amps", 20},
+		{2019, 8, "width", 0},
+		{2019, 8, "width", -25},
+		{2019, 8, "height", -6},
+		{2019, 10, "width", -1},
+		{2019, 10, "height", 1 << 40},
+		{2019, 10, "bet", 0},
+		{2019, 12, "steps", -1},
+	}
+	for _, tt := range tests {
+		e, ok := solutions.Registry.Lookup(tt.year, tt.day)
+		if !ok {
+			t.Errorf("no solver for year %d day %d", tt.year, tt.day)
+			continue
+		}
+		if _, err := e.NewEnv(nil, map[string]int{tt.param: tt.value}); !errors.Is(err, solutions.ErrParamRange) {
+			t.Errorf("%v with %s=%d: got error %v, want %v", e.Key, tt.param, tt.value, err, solutions.ErrParamRange)
+		}
+	}
+}
+
+// TestLayerBiggerThanImage checks that day 8 fails, rather than crashing, when its width and height are
+// in bounds but too big for the input.
+func TestLayerBiggerThanImage(t *testing.T) {
+	e, _ := solutions.Registry.Lookup(2019, 8)
+	env, err := e.NewEnv(nil, map[string]int{"width": 1000, "height": 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Solver("123456", env); err == nil {
+		t.Error("solved an image smaller than one layer")
+	}
+}
@@ -0,0 +1,112 @@
+package solutiontest
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+)
+
+// Benchmark measures one part of a solver.
+type Benchmark struct {
+	Key  solutions.Key
+	Part int
+	// Name identifies the benchmark among all solvers' benchmarks, e.g. "2019-10/part1".
+	Name string
+	// Source says where the benchmark's input came from.
+	Source string
+	// Skip explains why the benchmark can't run. It's empty if it can run.
+	Skip string
+
+	entry  solutions.Entry
+	input  string
+	params map[string]int
+}
+
+// Benchmarks returns a benchmark for each part of a registered solver.
+// They use the cached real input if there is one, then a generated input of realistic size
+// if the puzzle has a generator, and otherwise the solver's first example.
+func Benchmarks(r *solutions.Solvers, k solutions.Key) ([]Benchmark, error) {
+	entry, ok := r.Lookup(k.Year, k.Day)
+	if !ok {
+		return nil, fmt.Errorf("no solver registered for %v", k)
+	}
+
+	bm := Benchmark{Key: k, entry: entry}
+	in, ok, err := input.Cached(k.Year, k.Day)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok:
+		bm.input, bm.Source = in, "input"
+	case entry.Generator != nil:
+		bm.input, bm.Source = Generate(entry, GeneratedSeeds[0]), fmt.Sprintf("generated/seed-%d", GeneratedSeeds[0])
+	default:
+		exs, err := examples.Load(k)
+		if err != nil {
+			return nil, err
+		}
+		if len(exs) > 0 {
+			bm.input, bm.params, bm.Source = exs[0].Input, exs[0].Params, "examples/"+exs[0].Name
+		} else {
+			bm.Skip = "no cached input, generator or examples"
+		}
+	}
+
+	bms := make([]Benchmark, 2)
+	for i := range bms {
+		bms[i] = bm
+		bms[i].Part = i + 1
+		bms[i].Name = fmt.Sprintf("%v/part%d", k, i+1)
+	}
+	return bms, nil
+}
+
+// Run runs the benchmark's part b.N times.
+func (bm *Benchmark) Run(b *testing.B) {
+	if bm.Skip != "" {
+		b.Skip(bm.Skip)
+	}
+	env, err := bm.entry.NewEnv(nil, bm.params)
+	if err != nil {
+		b.Fatal(err)
+	}
+	env.Part = bm.Part
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := bm.entry.Solver(bm.input, env); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Bench runs the benchmarks for every solver in r as sub-benchmarks of b, grouped by year and day.
+func Bench(b *testing.B, r *solutions.Solvers) {
+	for _, k := range r.Keys() {
+		bms, err := Benchmarks(r, k)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for i := range bms {
+			b.Run(bms[i].Name, bms[i].Run)
+		}
+	}
+}
+
+// BenchCommand returns a command that runs BenchmarkSolutions from the repository's tests for a year,
+// or a single day if day isn't 0, with go test -bench. It needs Go installed and the repository's source.
+func BenchCommand(year int, day int) *exec.Cmd {
+	pattern := fmt.Sprintf("^BenchmarkSolutions$/^%d-", year)
+	if day != 0 {
+		pattern = fmt.Sprintf("^BenchmarkSolutions$/^%v$/", solutions.Key{Year: year, Day: day})
+	}
+	cmd := exec.Command("go", "test", "-run", "^$", "-bench", pattern, "-benchmem", ".")
+	cmd.Dir = examples.Root()
+	return cmd
+}
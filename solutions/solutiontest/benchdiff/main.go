@@ -0,0 +1,148 @@
+// Command benchdiff compares two sets of benchmark results and flags the ones that got slower or allocate more.
+//
+// Both files hold output in the format of go test -bench, such as that of `adventofcode-go -bench`.
+// When a benchmark appears more than once in a file, e.g. from -count, its measurements are averaged.
+// benchdiff exits with a nonzero status if anything regressed by more than the threshold.
+//
+//	benchdiff [-threshold 0.1] old.txt new.txt
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// measurements of a benchmark, keyed by unit, like "ns/op"
+type measurements map[string]float64
+
+// units that are compared, in display order
+var units = [...]string{"ns/op", "B/op", "allocs/op"}
+
+// matches the GOMAXPROCS suffix go test adds to benchmark names
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// parse reads benchmark results from a file and averages repeated runs of the same benchmark.
+func parse(path string) (map[string]measurements, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := make(map[string]measurements)
+	counts := make(map[string]map[string]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if sums[name] == nil {
+			sums[name], counts[name] = make(measurements), make(map[string]int)
+		}
+		// the rest of the line alternates between values and their units
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			sums[name][fields[i+1]] += v
+			counts[name][fields[i+1]]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for name, m := range sums {
+		for unit := range m {
+			m[unit] /= float64(counts[name][unit])
+		}
+	}
+	return sums, nil
+}
+
+// change returns the relative change from old to new, e.g. 0.25 for 25% more.
+func change(old, new float64) float64 {
+	if old == 0 {
+		if new == 0 {
+			return 0
+		}
+		return 1
+	}
+	return (new - old) / old
+}
+
+func main() {
+	threshold := flag.Float64("threshold", 0.1, "relative increase beyond which a measurement counts as a regression")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-threshold fraction] <old> <new>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	old, err := parse(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchdiff:", err)
+		os.Exit(1)
+	}
+	new, err := parse(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchdiff:", err)
+		os.Exit(1)
+	}
+
+	names := make([]string, 0, len(new))
+	for name := range new {
+		if _, ok := old[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "benchmark\tunit\told\tnew\tdelta\t")
+	var regressed int
+	for _, name := range names {
+		for _, unit := range units {
+			o, ok1 := old[name][unit]
+			n, ok2 := new[name][unit]
+			if !ok1 || !ok2 {
+				continue
+			}
+			delta := change(o, n)
+			var flagged string
+			if delta > *threshold {
+				flagged = "REGRESSION"
+				regressed++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.0f\t%.0f\t%+.1f%%\t%s\n", name, unit, o, n, delta*100, flagged)
+		}
+	}
+	w.Flush()
+
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			fmt.Fprintf(os.Stderr, "benchdiff: %s is missing from %s\n", name, flag.Arg(1))
+		}
+	}
+	if regressed > 0 {
+		fmt.Fprintf(os.Stderr, "benchdiff: %d measurements regressed by more than %.0f%%\n", regressed, *threshold*100)
+		os.Exit(1)
+	}
+}
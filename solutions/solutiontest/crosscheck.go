@@ -0,0 +1,223 @@
+package solutiontest
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+)
+
+// CrossCheckRuns is the number of generated inputs each solver's implementations are compared on by default.
+const CrossCheckRuns = 50
+
+// outcome is what one implementation made of an input.
+type outcome struct {
+	answers [2]string
+	err     error
+}
+
+func (o outcome) String() string {
+	if o.err != nil {
+		return "error: " + o.err.Error()
+	}
+	return fmt.Sprintf("part 1 %q, part 2 %q", o.answers[0], o.answers[1])
+}
+
+// solveWith runs one implementation on an input with the default parameters, turning a panic into an error.
+func solveWith(e solutions.Entry, s solutions.Solver, input string) (o outcome) {
+	defer func() {
+		if r := recover(); r != nil {
+			o.err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	env, err := e.NewEnv(nil, nil)
+	if err != nil {
+		return outcome{err: err}
+	}
+	sol, err := s(input, env)
+	if err != nil {
+		return outcome{err: err}
+	}
+	return outcome{answers: [2]string{fmt.Sprint(sol.Part1), fmt.Sprint(sol.Part2)}}
+}
+
+// Disagreement is an input that a solver's implementations don't all solve the same way.
+type Disagreement struct {
+	Key solutions.Key
+	// Seed is the seed the input was originally generated from.
+	Seed  int64
+	Input string
+
+	entry    solutions.Entry
+	outcomes map[string]outcome
+}
+
+func (d *Disagreement) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v implementations disagree on input from seed %d:", d.Key, d.Seed)
+	for _, name := range d.entry.ImplNames() {
+		fmt.Fprintf(&b, "\n\t%s: %v", name, d.outcomes[name])
+	}
+	return b.String()
+}
+
+// compare runs every implementation of the entry on an input. It returns their outcomes and whether they differ.
+// Inputs that the default implementation can't solve are treated as invalid rather than as disagreements,
+// unless strict is set.
+func compare(e solutions.Entry, input string, strict bool) (outcomes map[string]outcome, differ bool) {
+	outcomes = make(map[string]outcome)
+	ref := solveWith(e, e.Solver, input)
+	outcomes[solutions.DefaultImpl] = ref
+	if ref.err != nil && !strict {
+		return outcomes, false
+	}
+	for _, name := range e.ImplNames()[1:] {
+		o := solveWith(e, e.Impls[name], input)
+		outcomes[name] = o
+		if o.err != nil || ref.err != nil || o.answers != ref.answers {
+			differ = true
+		}
+	}
+	return
+}
+
+// FindDisagreement solves generated inputs with every implementation of a registered solver and returns the first
+// input they disagree on, shrunk to as small a reproducer as possible. It returns nil if they always agree, or if
+// the solver has a single implementation or no generator.
+// Inputs grow with the seed, so the earliest disagreements found tend to be small already.
+func FindDisagreement(r *solutions.Solvers, k solutions.Key, runs int) (*Disagreement, error) {
+	e, ok := r.Lookup(k.Year, k.Day)
+	if !ok {
+		return nil, fmt.Errorf("no solver registered for %v", k)
+	}
+	if len(e.Impls) == 0 || e.Generator == nil {
+		return nil, nil
+	}
+	for seed := int64(1); seed <= int64(runs); seed++ {
+		input := e.Generator(rand.New(rand.NewSource(seed)), int(seed)*10)
+		if _, differ := compare(e, input, true); !differ {
+			continue
+		}
+		input = Shrink(input, func(candidate string) bool {
+			_, differ := compare(e, candidate, false)
+			return differ
+		})
+		outcomes, _ := compare(e, input, true)
+		return &Disagreement{Key: k, Seed: seed, Input: input, entry: e, outcomes: outcomes}, nil
+	}
+	return nil, nil
+}
+
+// SaveExample stores the disagreement's input as a new example named after its seed, with the default
+// implementation's answers as the expected ones. Those answers still need checking by hand, since
+// either implementation could be the wrong one.
+func (d *Disagreement) SaveExample() (*examples.Example, error) {
+	ex := &examples.Example{Name: fmt.Sprintf("shrunk-%d", d.Seed), Input: d.Input}
+	if ref := d.outcomes[solutions.DefaultImpl]; ref.err == nil {
+		ex.Part1, ex.Part2 = &ref.answers[0], &ref.answers[1]
+	}
+	return ex, examples.Save(d.Key, ex)
+}
+
+// matches integers, which are shrunk toward zero
+var integerPattern = regexp.MustCompile(`-?\d+`)
+
+// Shrink repeatedly simplifies an input for as long as fails still holds for the simpler one, and returns the
+// simplest input found. It knows nothing about any puzzle's format; it drops runs of lines and of comma-separated
+// items and moves integers toward zero. Candidates that fails rejects, including ones the puzzle can't parse,
+// are skipped, so fails should return false for invalid inputs.
+func Shrink(input string, fails func(string) bool) string {
+	for {
+		shrunk := false
+		for _, candidates := range [...]func(string) []string{dropLines, dropItems, shrinkIntegers} {
+			for _, c := range candidates(input) {
+				if fails(c) {
+					input, shrunk = c, true
+					break
+				}
+			}
+			if shrunk {
+				break
+			}
+		}
+		if !shrunk {
+			return input
+		}
+	}
+}
+
+// dropRuns returns the ways to remove a run of parts, longest runs first.
+func dropRuns(parts []string, sep string) (candidates []string) {
+	for n := len(parts) / 2; n > 0; n /= 2 {
+		for i := 0; i+n <= len(parts); i += n {
+			kept := append(append([]string{}, parts[:i]...), parts[i+n:]...)
+			candidates = append(candidates, strings.Join(kept, sep))
+		}
+	}
+	return
+}
+
+func dropLines(input string) []string {
+	return dropRuns(strings.Split(input, "\n"), "\n")
+}
+
+func dropItems(input string) (candidates []string) {
+	lines := strings.Split(input, "\n")
+	for i, line := range lines {
+		for _, c := range dropRuns(strings.Split(line, ","), ",") {
+			shorter := append([]string{}, lines...)
+			shorter[i] = c
+			candidates = append(candidates, strings.Join(shorter, "\n"))
+		}
+	}
+	return
+}
+
+func shrinkIntegers(input string) (candidates []string) {
+	for _, loc := range integerPattern.FindAllStringIndex(input, -1) {
+		// a minus sign right after a digit is a separator, as in "123-456", not a sign
+		if input[loc[0]] == '-' && loc[0] > 0 && input[loc[0]-1] >= '0' && input[loc[0]-1] <= '9' {
+			loc[0]++
+		}
+		n, err := strconv.Atoi(input[loc[0]:loc[1]])
+		if err != nil || n == 0 {
+			continue
+		}
+		// try zero first, then values ever closer to n
+		for delta := n; delta != 0; delta /= 2 {
+			candidates = append(candidates, input[:loc[0]]+strconv.Itoa(n-delta)+input[loc[1]:])
+		}
+	}
+	return
+}
+
+// CrossCheck compares the implementations of every solver in r that has more than one, as subtests of t grouped
+// by year and day. Each disagreement is shrunk and saved as a new example.
+func CrossCheck(t *testing.T, r *solutions.Solvers, runs int) {
+	for _, k := range r.Keys() {
+		k := k
+		if e, _ := r.Lookup(k.Year, k.Day); len(e.Impls) == 0 {
+			continue
+		}
+		t.Run(k.String(), func(t *testing.T) {
+			d, err := FindDisagreement(r, k, runs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d == nil {
+				return
+			}
+			t.Error(d)
+			ex, err := d.SaveExample()
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("saved the shrunk input as example %s in %s; check its expected answers", ex.Name, examples.Dir(k))
+		})
+	}
+}
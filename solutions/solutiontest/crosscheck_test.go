@@ -0,0 +1,59 @@
+package solutiontest
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// anyInt reports whether any comma- or line-separated item of s is an integer that ok accepts.
+func anyInt(ok func(int) bool) func(string) bool {
+	return func(s string) bool {
+		for _, line := range strings.Split(s, "\n") {
+			for _, item := range strings.Split(line, ",") {
+				if n, err := strconv.Atoi(item); err == nil && ok(n) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+func TestShrink(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		fails func(string) bool
+		want  string
+	}{
+		{"never fails", "1,2\n3", func(string) bool { return false }, "1,2\n3"},
+		{"drops lines", "a\nb\nx\nc\nd", func(s string) bool { return strings.Contains(s, "x") }, "x"},
+		{"drops items", "a,b,c,x,d,e", func(s string) bool { return strings.Contains(s, "x") }, "x"},
+		{"drops items across lines", "a,x\nb,c\nd,y", func(s string) bool {
+			return strings.Contains(s, "x") && strings.Contains(s, "y")
+		}, "x\ny"},
+		{"moves integers to zero", "5,123,-40", func(s string) bool { return s != "" }, "0"},
+		{"stops at the smallest failing integer", "5,123,-40", anyInt(func(n int) bool { return n >= 17 }), "17"},
+		{"stops at the largest failing negative integer", "5,123,-40", anyInt(func(n int) bool { return n <= -7 }), "-7"},
+		{"minus between digits is a separator", "123-456", func(s string) bool { return strings.Contains(s, "-") }, "0-0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Shrink(tt.input, tt.fails); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShrinkOnlyKeepsFailingInputs(t *testing.T) {
+	fails := func(s string) bool { return strings.Count(s, ",") >= 2 && strings.Contains(s, "9") }
+	got := Shrink("1,9,3,4,5\n6,7", fails)
+	if !fails(got) {
+		t.Errorf("shrunk to %q, which doesn't fail", got)
+	}
+	if want := "0,9,0"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
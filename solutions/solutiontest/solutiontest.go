@@ -0,0 +1,172 @@
+// Package solutiontest checks registered solvers against their stored examples and against the
+// answers in the ledger for cached real inputs.
+//
+// Call Run from a test to check every solver in a registry as subtests, and Bench from a benchmark
+// to time each part of every solver:
+//
+//	func TestSolutions(t *testing.T) {
+//		solutiontest.Run(t, solutions.Registry)
+//	}
+//
+//	func BenchmarkSolutions(b *testing.B) {
+//		solutiontest.Bench(b, solutions.Registry)
+//	}
+package solutiontest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/ledger"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+)
+
+// GeneratedSeeds are the seeds of the synthetic inputs that solvers with a generator are checked against.
+var GeneratedSeeds = [...]int64{1, 2, 3}
+
+// Generate makes the synthetic input for a seed with the entry's generator, at the size of a real input.
+func Generate(e solutions.Entry, seed int64) string {
+	return e.Generator(rand.New(rand.NewSource(seed)), 0)
+}
+
+// Case is a single check of a solver.
+type Case struct {
+	Key solutions.Key
+	// Name identifies the case among the solver's cases, e.g. "examples/1" or "input".
+	Name string
+	// Skip explains why the case can't run. It's empty if the case can run.
+	Skip string
+
+	entry   solutions.Entry
+	example examples.Example
+	// part is the only part to solve for, or 0 for both
+	part int
+}
+
+// Run solves the case's input and returns an error if the solver fails, panics, or gets a wrong answer.
+// Diagnostics go to d.
+func (c *Case) Run(d *diag.Sink) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: solver panicked: %v", c.Name, r)
+		}
+	}()
+	if c.part != 0 {
+		return c.runPart(d)
+	}
+	_, err = c.example.Run(c.entry, d)
+	return
+}
+
+// runPart solves the case's input for only one part, and checks that the solver answers that part correctly
+// and leaves the other out, so that benchmarking one part doesn't time both.
+func (c *Case) runPart(d *diag.Sink) error {
+	env, err := c.entry.NewEnv(d, c.example.Params)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+	env.Part = c.part
+	s, err := c.entry.Solver(c.example.Input, env)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+	ex := c.example
+	if c.part == 1 {
+		ex.Part2 = nil
+		if s.Part2 != nil {
+			return fmt.Errorf("%s: solver answered part 2 when only part 1 was wanted", c.Name)
+		}
+	} else {
+		ex.Part1 = nil
+		if s.Part1 != nil {
+			return fmt.Errorf("%s: solver answered part 1 when only part 2 was wanted", c.Name)
+		}
+	}
+	return ex.Check(s)
+}
+
+// Cases returns the checks for a registered solver: one per stored example, one for each part alone
+// on the first example, one for the cached real input, and one per generated input if the puzzle has a generator.
+// A solver without examples gets a single skipped "examples" case, so that it doesn't silently pass.
+// The input case is skipped if the input hasn't been cached or the ledger has no answers for it.
+// Generated inputs have no known answers, so those cases only check that the solver succeeds.
+func Cases(r *solutions.Solvers, k solutions.Key, l *ledger.Ledger) ([]Case, error) {
+	entry, ok := r.Lookup(k.Year, k.Day)
+	if !ok {
+		return nil, fmt.Errorf("no solver registered for %v", k)
+	}
+	exs, err := examples.Load(k)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]Case, 0, len(exs)+1)
+	for _, ex := range exs {
+		cases = append(cases, Case{Key: k, Name: "examples/" + ex.Name, entry: entry, example: ex})
+	}
+	if len(exs) == 0 {
+		cases = append(cases, Case{Key: k, Name: "examples", Skip: "no examples in " + examples.Dir(k)})
+	} else {
+		for part := 1; part <= 2; part++ {
+			name := fmt.Sprintf("examples/%s/part%d", exs[0].Name, part)
+			cases = append(cases, Case{Key: k, Name: name, entry: entry, example: exs[0], part: part})
+		}
+	}
+
+	actual := Case{Key: k, Name: "input", entry: entry}
+	in, ok, err := input.Cached(k.Year, k.Day)
+	switch {
+	case err != nil:
+		return nil, err
+	case !ok:
+		actual.Skip = "input not cached"
+	default:
+		if answers, ok := l.Get(k); ok {
+			actual.example = examples.Example{Name: "input", Input: in, Part1: answers.Part1, Part2: answers.Part2}
+		} else {
+			actual.Skip = "no answers in the ledger"
+		}
+	}
+	cases = append(cases, actual)
+
+	if entry.Generator != nil {
+		for _, seed := range GeneratedSeeds {
+			name := fmt.Sprintf("generated/seed-%d", seed)
+			ex := examples.Example{Name: name, Input: Generate(entry, seed)}
+			cases = append(cases, Case{Key: k, Name: name, entry: entry, example: ex})
+		}
+	}
+	return cases, nil
+}
+
+// Run runs the cases for every solver in r as subtests of t, grouped by year and day.
+func Run(t *testing.T, r *solutions.Solvers) {
+	l, err := ledger.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range r.Keys() {
+		k := k
+		t.Run(k.String(), func(t *testing.T) {
+			cases, err := Cases(r, k, l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range cases {
+				c := &cases[i]
+				t.Run(c.Name, func(t *testing.T) {
+					if c.Skip != "" {
+						t.Skip(c.Skip)
+					}
+					if err := c.Run(nil); err != nil {
+						t.Error(err)
+					}
+				})
+			}
+		})
+	}
+}
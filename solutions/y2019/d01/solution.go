@@ -8,6 +8,9 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions"
 )
 
+// Title is the name of the puzzle.
+const Title = "The Tyranny of the Rocket Equation"
+
 func part1(masses []int) (sum int) {
 	// unnecessary concurrency woo!
 	c := make(chan int)
@@ -58,7 +61,7 @@ func part2(masses []int) (sum int) {
 }
 
 // Solve provides the day 1 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	lines := strings.Split(input, "\n")
 	var masses []int
 	for _, line := range lines {
@@ -68,5 +71,12 @@ func Solve(input string) (*solutions.Solution, error) {
 		}
 		masses = append(masses, mass)
 	}
-	return &solutions.Solution{Part1: part1(masses), Part2: part2(masses)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = part1(masses)
+	}
+	if env.Wants(2) {
+		solution.Part2 = part2(masses)
+	}
+	return solution, nil
 }
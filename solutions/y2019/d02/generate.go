@@ -0,0 +1,65 @@
+package d02
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// number of instructions in a real puzzle input, give or take
+const realSize = 30
+
+// Generate makes a random program that, like a real one, outputs the noun times some number of at least 100,
+// plus the verb, plus a constant. It adds size constants along the way, and some noun and verb between 0 and 99
+// make it output the default target.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	if size < 1 {
+		size = 1
+	}
+	noun, verb := rng.Intn(100), rng.Intn(100)
+	// with a scale of at least 100, no other noun and verb give the same output
+	var factors []int
+	scale := 1
+	for scale < 100 {
+		f := 2 + rng.Intn(8)
+		factors = append(factors, f)
+		scale *= f
+	}
+	// split what's left of the target into size constants
+	cuts := make([]int, size+1)
+	cuts[size] = defaultTarget - scale*noun - verb
+	for i := 1; i < size; i++ {
+		cuts[i] = rng.Intn(cuts[size] + 1)
+	}
+	sort.Ints(cuts)
+	addends := make([]int, size)
+	for i := range addends {
+		addends[i] = cuts[i+1] - cuts[i]
+	}
+
+	// the data goes after the code: an accumulator, a zero, the factors and the addends
+	codeLen := 4 + 4 + 4*len(factors) + 4*size + 4 + 1
+	acc, zero := codeLen, codeLen+1
+	// the first instruction reads whatever is at the noun and verb, as a real program's does
+	code := []int{1, 0, 0, 3, 1, 1, zero, acc}
+	data := []int{0, 0}
+	for _, f := range factors {
+		code = append(code, 2, acc, codeLen+len(data), acc)
+		data = append(data, f)
+	}
+	for _, a := range addends {
+		code = append(code, 1, acc, codeLen+len(data), acc)
+		data = append(data, a)
+	}
+	code = append(code, 1, acc, 2, 0, 99)
+
+	numbers := make([]string, 0, len(code)+len(data))
+	for _, n := range append(code, data...) {
+		numbers = append(numbers, strconv.Itoa(n))
+	}
+	return strings.Join(numbers, ",")
+}
@@ -5,18 +5,29 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
 )
 
+// Title is the name of the puzzle.
+const Title = "1202 Program Alarm"
+
 func part1(initMem interpreter.Program) (int, error) {
 	return interpreter.NewWithNounVerb(initMem, 12, 2, nil, nil).Run()
 }
 
-func part2(initMem interpreter.Program) (int, error) {
+// output that the real puzzle asks for in part 2
+const defaultTarget = 19690720
+
+// Params are the tunable values for this puzzle.
+var Params = []solutions.Param{
+	{Name: "target", Default: defaultTarget, Usage: "output that part 2 searches for a noun and verb to produce"},
+}
+
+func part2(initMem interpreter.Program, target int) (int, error) {
 	for noun := 0; noun < 100; noun++ {
 		for verb := 0; verb < 100; verb++ {
 			result, err := interpreter.NewWithNounVerb(initMem, noun, verb, nil, nil).Run()
 			if err != nil {
 				return 0, err
 			}
-			if result == 19690720 {
+			if result == target {
 				return 100*noun + verb, nil
 			}
 		}
@@ -25,16 +36,23 @@ func part2(initMem interpreter.Program) (int, error) {
 }
 
 // Solve provides the day 2 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	initMem := interpreter.ParseMem(input)
 
-	answer1, err := part1(initMem)
-	if err != nil {
-		return nil, err
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		answer1, err := part1(initMem)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part1 = answer1
 	}
-	answer2, err := part2(initMem)
-	if err != nil {
-		return nil, err
+	if env.Wants(2) {
+		answer2, err := part2(initMem, env.Param("target"))
+		if err != nil {
+			return nil, err
+		}
+		solution.Part2 = answer2
 	}
-	return &solutions.Solution{Part1: answer1, Part2: answer2}, nil
+	return solution, nil
 }
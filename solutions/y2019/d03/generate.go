@@ -0,0 +1,50 @@
+package d03
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+const (
+	// number of segments in each wire of a real puzzle input, give or take
+	realSize = 300
+	// longest segment in a real puzzle input, give or take
+	maxMagnitude = 1000
+)
+
+// Generate makes two random wire paths with size segments each.
+// Smaller sizes also make shorter segments, so that the wires cross themselves and each other more often.
+// The wires always cross at least once away from the origin.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	if size < 2 {
+		size = 2
+	}
+	longest := maxMagnitude
+	if size < longest {
+		longest = size
+	}
+
+	// start the wires with legs that are sure to cross: one goes up then right, the other right then up
+	leg := longest/10 + 1
+	a, b := 1+rng.Intn(leg), 1+rng.Intn(leg)
+	wires := [2][]string{
+		{fmt.Sprintf("U%d", a), fmt.Sprintf("R%d", b+1+rng.Intn(leg))},
+		{fmt.Sprintf("R%d", b), fmt.Sprintf("U%d", a+1+rng.Intn(leg))},
+	}
+	turns := [...]string{"UD", "LR"}
+	for i := range wires {
+		// U and D alternate with L and R, like in real inputs.
+		// The first wire's start ended going right and the second's going up.
+		axis := 1 - i
+		for len(wires[i]) < size {
+			axis = 1 - axis
+			dir := turns[axis][rng.Intn(2)]
+			wires[i] = append(wires[i], fmt.Sprintf("%c%d", dir, 1+rng.Intn(longest)))
+		}
+	}
+	return strings.Join(wires[0], ",") + "\n" + strings.Join(wires[1], ",")
+}
@@ -0,0 +1,122 @@
+package d03
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+// segment is a straight stretch of wire, starting just after start and extending length units in direction d.
+type segment struct {
+	start  point
+	d      *dir
+	length int
+	// path distance traveled to reach start
+	dist int
+}
+
+// at returns the point n units into the segment.
+func (s *segment) at(n int) point {
+	return point{s.start.x + n*s.d.x, s.start.y + n*s.d.y}
+}
+
+// stepsTo returns how many units into the segment p is. p must be on the segment.
+func (s *segment) stepsTo(p point) int {
+	if s.d.x != 0 {
+		return (p.x - s.start.x) * s.d.x
+	}
+	return (p.y - s.start.y) * s.d.y
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// bounds returns the corners of the smallest box containing every point on the segment.
+func (s *segment) bounds() (lo, hi point) {
+	first, last := s.at(1), s.at(s.length)
+	lo = point{minInt(first.x, last.x), minInt(first.y, last.y)}
+	hi = point{maxInt(first.x, last.x), maxInt(first.y, last.y)}
+	return
+}
+
+// crossings returns the points shared by two segments along with the total path distance of both wires to each point.
+// Perpendicular segments share at most one point, but overlapping parallel ones can share many.
+func crossings(s1, s2 *segment) (shared []cursor) {
+	if s1.length == 0 || s2.length == 0 {
+		return
+	}
+	lo1, hi1 := s1.bounds()
+	lo2, hi2 := s2.bounds()
+	lo := point{maxInt(lo1.x, lo2.x), maxInt(lo1.y, lo2.y)}
+	hi := point{minInt(hi1.x, hi2.x), minInt(hi1.y, hi2.y)}
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			p := point{x, y}
+			shared = append(shared, cursor{p, s1.dist + s1.stepsTo(p) + s2.dist + s2.stepsTo(p)})
+		}
+	}
+	return
+}
+
+// getSegments decomposes the input into the straight segments of each wire.
+func getSegments(input string) (wires [2][]segment) {
+	for i, wire := range strings.Split(input, "\n") {
+		var c cursor
+		for _, vec := range strings.Split(wire, ",") {
+			mag, _ := strconv.Atoi(vec[1:])
+			s := segment{start: c.p, d: dirs[vec[0]], length: mag, dist: c.dist}
+			wires[i] = append(wires[i], s)
+			c = cursor{s.at(mag), c.dist + mag}
+		}
+	}
+	return
+}
+
+// solveSegments finds the answers to parts 1 and 2 by intersecting every pair of segments
+// instead of visiting every point on the wires.
+func solveSegments(wires [2][]segment) (minDist, minPathLength int) {
+	found := false
+	for i := range wires[0] {
+		for j := range wires[1] {
+			for _, c := range crossings(&wires[0][i], &wires[1][j]) {
+				if c.p == origin {
+					continue
+				}
+				dist := manhattan(origin, c.p)
+				if !found || dist < minDist {
+					minDist = dist
+				}
+				if !found || c.dist < minPathLength {
+					minPathLength = c.dist
+				}
+				found = true
+			}
+		}
+	}
+	return
+}
+
+// SolveSegments provides the day 3 puzzle solution using segment intersection.
+func SolveSegments(input string, env *solutions.Env) (*solutions.Solution, error) {
+	minDist, minPathLength := solveSegments(getSegments(input))
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = minDist
+	}
+	if env.Wants(2) {
+		solution.Part2 = minPathLength
+	}
+	return solution, nil
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions"
 )
 
+// Title is the name of the puzzle.
+const Title = "Crossed Wires"
+
 var (
 	origin = point{}
 	dirs   = map[byte]*dir{
@@ -79,9 +82,13 @@ func (g *grid) draw(d *dir, wireNum int) {
 	if !ok {
 		records = [2]record{}
 	}
-	records[wireNum].visited = true
-	records[wireNum].pathLength = g.c[wireNum].dist
-	g.setRecordsAtCursor(wireNum, records)
+	// only the first visit counts toward the path length, since the wire could have stopped there;
+	// examples/revisit has a wire that comes back over its own path
+	if !records[wireNum].visited {
+		records[wireNum].visited = true
+		records[wireNum].pathLength = g.c[wireNum].dist
+		g.setRecordsAtCursor(wireNum, records)
+	}
 }
 
 // draw a full wire on the grid based on the given move set.
@@ -91,13 +98,14 @@ func (g *grid) drawPath(moveSet []*dir, wireNum int) {
 	}
 }
 
-// intersections finds all points in the grid where the wires crossed,
+// intersections finds all points in the grid other than the origin where the wires crossed,
 // and returns a slice of cursors giving the intersection points and
 // the total distances traveled by the wires at the time they crossed.
 func (g *grid) intersections() []cursor {
 	shared := make([]cursor, 0, len(g.g))
 	for p, records := range g.g {
-		if records[0].visited && records[1].visited {
+		// the wires both start at the origin, but that doesn't count as crossing, even if both come back to it
+		if p != origin && records[0].visited && records[1].visited {
 			shared = append(shared, cursor{p, records[0].pathLength + records[1].pathLength})
 		}
 	}
@@ -158,7 +166,15 @@ func solve(moves [2][]*dir) (minDist, minPathLength int) {
 }
 
 // Solve provides the day 3 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
+	// both parts need the whole grid, so there's no work to skip when only one is wanted
 	minDist, minPathLength := solve(getMoves(input))
-	return &solutions.Solution{Part1: minDist, Part2: minPathLength}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = minDist
+	}
+	if env.Wants(2) {
+		solution.Part2 = minPathLength
+	}
+	return solution, nil
 }
@@ -0,0 +1,88 @@
+package d04
+
+import (
+	"strconv"
+
+	"github.com/jzimbel/adventofcode-go/solutions"
+)
+
+const passwordLength = 6
+
+// digitCounts holds how many of each digit a password has.
+type digitCounts [10]int
+
+// number returns the only 6-digit number with non-decreasing digits that has these digit counts.
+func (dc *digitCounts) number() (n int) {
+	for d := range dc {
+		for i := 0; i < dc[d]; i++ {
+			n = n*10 + d
+		}
+	}
+	return
+}
+
+// eachNonDecreasing calls f with the digit counts of every 6-digit number (leading zeros allowed) whose digits never decrease.
+// A number like that is fully determined by how many of each digit it has, so there are only C(15,6) = 5005 of them.
+func eachNonDecreasing(f func(*digitCounts)) {
+	var dc digitCounts
+	var fill func(digit, remaining int)
+	fill = func(digit, remaining int) {
+		if digit == len(dc)-1 {
+			dc[digit] = remaining
+			f(&dc)
+			return
+		}
+		for n := remaining; n >= 0; n-- {
+			dc[digit] = n
+			fill(digit+1, remaining-n)
+		}
+	}
+	fill(0, passwordLength)
+}
+
+// countCombinations counts the numbers in [lower,upper] with non-decreasing digits whose digit counts satisfy pred.
+func countCombinations(lower, upper int, pred func(*digitCounts) bool) (validCount int) {
+	eachNonDecreasing(func(dc *digitCounts) {
+		if n := dc.number(); n >= lower && n <= upper && pred(dc) {
+			validCount++
+		}
+	})
+	return
+}
+
+// Digits never decrease, so equal digits are always adjacent:
+// any digit appearing at least twice is a repeat, and one appearing exactly twice is a discrete 2-digit repeat.
+
+func hasRepeat(dc *digitCounts) bool {
+	for _, c := range dc {
+		if c >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPair(dc *digitCounts) bool {
+	for _, c := range dc {
+		if c == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// SolveCombinatorics provides the day 4 puzzle solution by building the candidate passwords
+// instead of checking every number in the range.
+func SolveCombinatorics(input string, env *solutions.Env) (*solutions.Solution, error) {
+	bounds := inputPattern.FindStringSubmatch(input)[1:]
+	lower, _ := strconv.Atoi(bounds[0])
+	upper, _ := strconv.Atoi(bounds[1])
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = countCombinations(lower, upper, hasRepeat)
+	}
+	if env.Wants(2) {
+		solution.Part2 = countCombinations(lower, upper, hasPair)
+	}
+	return solution, nil
+}
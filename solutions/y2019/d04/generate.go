@@ -0,0 +1,25 @@
+package d04
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// width of the range in a real puzzle input, give or take
+const realSize = 500000
+
+// Generate makes a random range of 6-digit numbers that's size numbers wide.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	const lowest, highest = 100000, 999999
+	if size > highest-lowest {
+		size = highest - lowest
+	}
+	if size < 1 {
+		size = 1
+	}
+	lower := lowest + rng.Intn(highest-lowest-size+1)
+	return fmt.Sprintf("%d-%d", lower, lower+size)
+}
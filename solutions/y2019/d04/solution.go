@@ -9,6 +9,9 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions/common"
 )
 
+// Title is the name of the puzzle.
+const Title = "Secure Container"
+
 type nothing struct{}
 
 var (
@@ -98,12 +101,16 @@ func solve(lower, upper int, pred func(int) bool) (validCount int) {
 }
 
 // Solve provides the day 4 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	bounds := inputPattern.FindStringSubmatch(input)[1:]
 	lower, _ := strconv.Atoi(bounds[0])
 	upper, _ := strconv.Atoi(bounds[1])
-	return &solutions.Solution{
-		Part1: solve(lower, upper, isValidPart1),
-		Part2: solve(lower, upper, isValidPart2),
-	}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = solve(lower, upper, isValidPart1)
+	}
+	if env.Wants(2) {
+		solution.Part2 = solve(lower, upper, isValidPart2)
+	}
+	return solution, nil
 }
@@ -1,13 +1,15 @@
 package d05
 
 import (
-	"fmt"
-
+	"github.com/jzimbel/adventofcode-go/diag"
 	"github.com/jzimbel/adventofcode-go/solutions"
 	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
 )
 
-func run(initMem interpreter.Program, systemID int) (int, error) {
+// Title is the name of the puzzle.
+const Title = "Sunny with a Chance of Asteroids"
+
+func run(initMem interpreter.Program, systemID int, d *diag.Sink) (int, error) {
 	var lastOutput int
 
 	_, err := interpreter.New(
@@ -17,7 +19,7 @@ func run(initMem interpreter.Program, systemID int) (int, error) {
 		},
 		func(n int) {
 			lastOutput = n
-			fmt.Println(n)
+			d.Infof("system %d diagnostic code: %d", systemID, n)
 		},
 	).Run()
 	if err != nil {
@@ -27,17 +29,23 @@ func run(initMem interpreter.Program, systemID int) (int, error) {
 }
 
 // Solve provides the day 5 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	initMem := interpreter.ParseMem(input)
 
-	answer1, err := run(initMem, 1)
-	if err != nil {
-		return nil, err
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		answer1, err := run(initMem, 1, env.Diag)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part1 = answer1
 	}
-	answer2, err := run(initMem, 5)
-	if err != nil {
-		return nil, err
+	if env.Wants(2) {
+		answer2, err := run(initMem, 5, env.Diag)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part2 = answer2
 	}
-
-	return &solutions.Solution{Part1: answer1, Part2: answer2}, nil
+	return solution, nil
 }
@@ -0,0 +1,48 @@
+package d06
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// number of objects in a real puzzle input, give or take
+const realSize = 1500
+
+const labelChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Generate makes a random orbit map with size objects besides COM, YOU and SAN.
+// YOU and SAN never orbit COM directly.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	labels := make([]string, 0, size+1)
+	used := map[string]bool{centerOfMass: true, you: true, santa: true}
+	labels = append(labels, centerOfMass)
+	orbits := make([]string, 0, size+2)
+	for len(labels) <= size {
+		label := make([]byte, 3)
+		for i := range label {
+			label[i] = labelChars[rng.Intn(len(labelChars))]
+		}
+		if used[string(label)] {
+			continue
+		}
+		used[string(label)] = true
+		orbits = append(orbits, fmt.Sprintf("%s)%s", labels[rng.Intn(len(labels))], label))
+		labels = append(labels, string(label))
+	}
+	// labels[0] is COM, so skip it when picking what YOU and SAN orbit
+	orbits = append(orbits,
+		fmt.Sprintf("%s)%s", labels[1+rng.Intn(len(labels)-1)], you),
+		fmt.Sprintf("%s)%s", labels[1+rng.Intn(len(labels)-1)], santa),
+	)
+
+	rng.Shuffle(len(orbits), func(i, j int) { orbits[i], orbits[j] = orbits[j], orbits[i] })
+	return strings.Join(orbits, "\n")
+}
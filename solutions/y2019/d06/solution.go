@@ -7,6 +7,9 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions"
 )
 
+// Title is the name of the puzzle.
+const Title = "Universal Orbit Map"
+
 // intermediate data structure to make building the tree easier
 type orbitMap map[string][]string
 
@@ -112,7 +115,14 @@ func part2(ft flatTree) uint {
 }
 
 // Solve provides the day 6 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	ft := makeFlatTree(parseInput(input))
-	return &solutions.Solution{Part1: part1(ft), Part2: part2(ft)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = part1(ft)
+	}
+	if env.Wants(2) {
+		solution.Part2 = part2(ft)
+	}
+	return solution, nil
 }
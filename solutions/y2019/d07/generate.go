@@ -0,0 +1,66 @@
+package d07
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+const (
+	// number of arithmetic steps an amplifier makes to a signal in a real puzzle input, give or take
+	realSize = 20
+	// how many signals an amplifier handles in a feedback loop before halting
+	loops = 10
+)
+
+// Generate makes a random amplifier program that reads its phase setting and then a signal, makes size
+// additions to it along with one doubling, and outputs it. Phase settings of 5 and up put it in feedback loop
+// mode, where it handles a number of signals before halting. The phase setting is among the things added,
+// so the order of the settings matters.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	// where the loop reading each signal starts
+	const loopStart = 17
+	codeLen := loopStart + 2 + 4*(size+1) + 2 + 4 + 3 + 1
+	// the phase, the number of signals left to handle, a scratch value and the signal go after the code
+	phase, left, scratch, signal := codeLen, codeLen+1, codeLen+2, codeLen+3
+	code := []int{
+		3, phase,
+		1101, 0, loops, left,
+		1007, phase, 5, scratch,
+		// in feedback loop mode, skip setting the number of signals to 1
+		1006, scratch, loopStart,
+		1101, 0, 1, left,
+		3, signal,
+	}
+	double := rng.Intn(size + 1)
+	for i := 0; i <= size; i++ {
+		switch {
+		case i == double:
+			code = append(code, 1002, signal, 2, signal)
+		case rng.Intn(3) == 0:
+			code = append(code, 1, signal, phase, signal)
+		default:
+			code = append(code, 1001, signal, 1+rng.Intn(9), signal)
+		}
+	}
+	code = append(code,
+		4, signal,
+		1001, left, -1, left,
+		1005, left, loopStart,
+		99,
+		0, 0, 0, 0,
+	)
+
+	numbers := make([]string, len(code))
+	for i, n := range code {
+		numbers[i] = strconv.Itoa(n)
+	}
+	return strings.Join(numbers, ",")
+}
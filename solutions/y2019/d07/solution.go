@@ -1,6 +1,7 @@
 package d07
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/jzimbel/adventofcode-go/solutions"
@@ -8,39 +9,45 @@ import (
 	"modernc.org/mathutil"
 )
 
-const (
-	ampCount          = 5
-	initialInput      = 0
-	minPhase     uint = 0
-	maxPhase     uint = 4
-)
+// Title is the name of the puzzle.
+const Title = "Amplification Circuit"
+
+const initialInput = 0
+
+// maxAmps bounds the amps parameter, since every ordering of the amplifiers' phase settings is tried at once
+const maxAmps = 8
+
+// Params are the tunable values for this puzzle.
+var Params = []solutions.Param{
+	{Name: "amps", Default: 5, Usage: "number of amplifiers in the series", Min: 1, Max: maxAmps},
+}
 
 // Implements sort.Interface to take advantage of mathutil Permutation functions
-type phaseSettings [ampCount]uint
+type phaseSettings []uint
 
-func (ps *phaseSettings) Len() int {
+func (ps phaseSettings) Len() int {
 	return len(ps)
 }
 
-func (ps *phaseSettings) Less(i, j int) bool {
+func (ps phaseSettings) Less(i, j int) bool {
 	return ps[i] < ps[j]
 }
 
-func (ps *phaseSettings) Swap(i, j int) {
+func (ps phaseSettings) Swap(i, j int) {
 	ps[i], ps[j] = ps[j], ps[i]
 }
 
-// phaseSettingsGenerator returns a channel that receives all permutations of phase settings and then closes.
-// ch1 := phaseSettingsGenerator(0)
+// phaseSettingsGenerator returns a channel that receives all permutations of phase settings for ampCount amplifiers and then closes.
+// ch1 := phaseSettingsGenerator(5, 0)
 // ch1 will receive [0 1 2 3 4], [0 1 2 4 3], ...
-// ch2 := phaseSettingsGenerator(5)
+// ch2 := phaseSettingsGenerator(5, 5)
 // ch2 will receive [5 6 7 8 9], [5 6 7 9 8], ...
-func phaseSettingsGenerator(offset uint) <-chan *phaseSettings {
-	ch := make(chan *phaseSettings)
+func phaseSettingsGenerator(ampCount uint, offset uint) <-chan phaseSettings {
+	ch := make(chan phaseSettings)
 
 	go func() {
 		defer close(ch)
-		ps := &phaseSettings{}
+		ps := make(phaseSettings, ampCount)
 		for i := uint(0); i < ampCount; i++ {
 			ps[i] = i + offset
 		}
@@ -48,8 +55,9 @@ func phaseSettingsGenerator(offset uint) <-chan *phaseSettings {
 
 		var done bool
 		for !done {
-			psCopy := *ps
-			ch <- &psCopy
+			psCopy := make(phaseSettings, len(ps))
+			copy(psCopy, ps)
+			ch <- psCopy
 			done = !mathutil.PermutationNext(ps)
 		}
 	}()
@@ -57,105 +65,75 @@ func phaseSettingsGenerator(offset uint) <-chan *phaseSettings {
 	return ch
 }
 
-// makeInputDevice returns an input function to be used by the amplifier intcode program.
-// The first time the input is called, it returns the phase setting for the amplifier.
-// All future calls return values received from the given channel.
-func makeInputDevice(phaseSetting uint, ch <-chan int) func() int {
-	callCount := 0
-	return func() (n int) {
-		defer func() { callCount++ }()
-		if callCount == 0 {
-			n = int(phaseSetting)
-		} else {
-			n = <-ch
-		}
-		return
-	}
-}
-
-// makeOutputDevice returns an output function to be used by the amplifier intcode program.
-// This function sends its argument to the given channel.
-func makeOutputDevice(ch chan<- int) func(int) {
-	return func(n int) {
-		ch <- n
-	}
-}
-
-// makeLoopingOutputDevice is like makeOutputDevice, but the function it produces sends values to
-// two given channels instead of one. This allows for signals to be passed in a loop but also received
-// by an outside function that's interested in the final output of the amplifiers.
-func makeLoopingOutputDevice(loop chan<- int, output chan<- int) func(int) {
-	return func(n int) {
-		loop <- n
-		output <- n
+// newAmplifiers makes an amplifier for each phase setting, each with its setting queued as its first input.
+func newAmplifiers(initMem interpreter.Program, settings phaseSettings) []*interpreter.Interpreter {
+	amps := make([]*interpreter.Interpreter, len(settings))
+	for i, setting := range settings {
+		amps[i] = interpreter.New(initMem, nil, nil)
+		amps[i].PushInput(int(setting))
 	}
+	return amps
 }
 
 // runAmplifiers runs a series of amplifiers with the given phase settings and returns their output.
-func runAmplifiers(initMem interpreter.Program, settings *phaseSettings) (signal int) {
+func runAmplifiers(initMem interpreter.Program, settings phaseSettings) (signal int, err error) {
 	// 0 -> Amp A -> Amp B -> Amp C -> Amp D -> Amp E -> (to thrusters)
-	// 5 amps, 6 channels
-	chs := [ampCount + 1]chan int{}
-	for i := range chs {
-		chs[i] = make(chan int)
+	net := interpreter.NewNetwork(newAmplifiers(initMem, settings), interpreter.Chain)
+	net.Send(interpreter.Packet{From: -1, To: 0, Values: []int{initialInput}})
+	if err := net.Run(); err != nil {
+		return 0, err
 	}
-
-	for i := 0; i < ampCount; i++ {
-		go func(icpy int) {
-			interpreter.New(initMem, makeInputDevice(settings[icpy], chs[icpy]), makeOutputDevice(chs[icpy+1])).Run()
-		}(i)
+	for _, p := range net.Receive() {
+		signal = p.Values[0]
 	}
+	return
+}
 
-	chs[0] <- initialInput
-	return <-chs[ampCount]
+// feedbackLoop routes amplifier output around the loop, and also sends the last amplifier's output out
+// to the thrusters, since the first amplifier stops reading it once it halts.
+func feedbackLoop(from int, size int, out []int) ([]interpreter.Packet, []int) {
+	packets, rest := interpreter.Ring(from, size, out)
+	if from == size-1 {
+		packets = append(packets, interpreter.Packet{From: from, To: -1, Values: out})
+	}
+	return packets, rest
 }
 
 // runAmplifierLoop runs a series of amplifiers in a loop with the given phase settings and returns their final output when they halt.
-func runAmplifierLoop(initMem interpreter.Program, settings *phaseSettings) (signal int) {
+func runAmplifierLoop(initMem interpreter.Program, settings phaseSettings) (signal int, err error) {
 	// 0 -> Amp A -> Amp B -> Amp C -> Amp D -> Amp E -> (to thrusters upon Amp E halt)
-	//    0        1        2        3        4        0
-	// 5 amps, 5 channels
-	chs := [ampCount]chan int{}
-	// use a buffered channel for the first channel so that it can receive
-	// one more value that won't be consumed during the final iteration of the loop
-	chs[0] = make(chan int, 1)
-	for i := range chs[1:] {
-		chs[i+1] = make(chan int)
+	//  ^                                            |
+	//  +--------------------------------------------+
+	net := interpreter.NewNetwork(newAmplifiers(initMem, settings), feedbackLoop)
+	net.Send(interpreter.Packet{From: -1, To: 0, Values: []int{initialInput}})
+	if err := net.Run(); err != nil {
+		return 0, err
 	}
-	// final amplifier also sends to this channel so that we can capture outputs
-	output := make(chan int)
-
-	for i := 0; i < ampCount; i++ {
-		go func(icpy int) {
-			var outDevice func(int)
-			if icpy == ampCount-1 {
-				// when this interpreter halts, the whole amplifier loop is done
-				defer close(output)
-				outDevice = makeLoopingOutputDevice(chs[(icpy+1)%ampCount], output)
-			} else {
-				outDevice = makeOutputDevice(chs[(icpy+1)%ampCount])
-			}
-			interpreter.New(initMem, makeInputDevice(settings[icpy], chs[icpy]), outDevice).Run()
-		}(i)
+	if !net.Halted() {
+		return 0, errors.New("the amplifiers stopped before halting, waiting for input")
 	}
-
-	chs[0] <- initialInput
-	var finalSignal int
-	for n := range output {
-		finalSignal = n
+	for _, p := range net.Receive() {
+		signal = p.Values[0]
 	}
-	return finalSignal
+	return
 }
 
-func run(initMem interpreter.Program, phaseSettingOffset uint, runner func(interpreter.Program, *phaseSettings) int) (maxSignal int) {
-	ch := make(chan int)
+// result is the outcome of running one set of amplifiers.
+type result struct {
+	signal int
+	err    error
+}
+
+func run(initMem interpreter.Program, ampCount uint, phaseSettingOffset uint, runner func(interpreter.Program, phaseSettings) (int, error)) (maxSignal int, err error) {
+	ch := make(chan result)
 	wg := sync.WaitGroup{}
 
-	for settings := range phaseSettingsGenerator(phaseSettingOffset) {
+	for settings := range phaseSettingsGenerator(ampCount, phaseSettingOffset) {
 		wg.Add(1)
-		go func(settings *phaseSettings) {
+		go func(settings phaseSettings) {
 			defer wg.Done()
-			ch <- runner(initMem, settings)
+			signal, err := runner(initMem, settings)
+			ch <- result{signal, err}
 		}(settings)
 	}
 
@@ -164,28 +142,55 @@ func run(initMem interpreter.Program, phaseSettingOffset uint, runner func(inter
 		wg.Wait()
 	}()
 
-	for signal := range ch {
-		if signal > maxSignal {
-			maxSignal = signal
+	for r := range ch {
+		if r.err != nil && err == nil {
+			err = r.err
+		}
+		if r.signal > maxSignal {
+			maxSignal = r.signal
 		}
 	}
 	return
 }
 
-func part1(initMem interpreter.Program, ch chan<- int) {
-	ch <- run(initMem, 0, runAmplifiers)
+func part1(initMem interpreter.Program, ampCount uint, ch chan<- result) {
+	signal, err := run(initMem, ampCount, 0, runAmplifiers)
+	ch <- result{signal, err}
 }
 
-func part2(initMem interpreter.Program, ch chan<- int) {
-	ch <- run(initMem, 5, runAmplifierLoop)
+// the feedback loop uses the phase settings just after the ones used in part 1
+func part2(initMem interpreter.Program, ampCount uint, ch chan<- result) {
+	signal, err := run(initMem, ampCount, ampCount, runAmplifierLoop)
+	ch <- result{signal, err}
 }
 
 // Solve provides the day 7 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	initMem := interpreter.ParseMem(input)
-	ch1, ch2 := make(chan int), make(chan int)
+	ch1, ch2 := make(chan result), make(chan result)
 
-	go part1(initMem, ch1)
-	go part2(initMem, ch2)
-	return &solutions.Solution{Part1: <-ch1, Part2: <-ch2}, nil
+	ampCount := uint(env.Param("amps"))
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		go part1(initMem, ampCount, ch1)
+	}
+	if env.Wants(2) {
+		go part2(initMem, ampCount, ch2)
+	}
+	var err error
+	if env.Wants(1) {
+		r := <-ch1
+		solution.Part1, err = r.signal, r.err
+	}
+	if env.Wants(2) {
+		r := <-ch2
+		solution.Part2 = r.signal
+		if err == nil {
+			err = r.err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return solution, nil
 }
@@ -1,18 +1,26 @@
 package d08
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/jzimbel/adventofcode-go/solutions"
 )
 
-const (
-	width     = 25
-	height    = 6
-	layerSize = width * height
-	// difference between byte values for e.g. '0' and 0, '1' and 1, etc.
-	asciiDigitDiff = 48
-)
+// Title is the name of the puzzle.
+const Title = "Space Image Format"
+
+// difference between byte values for e.g. '0' and 0, '1' and 1, etc.
+const asciiDigitDiff = 48
+
+// maxSide bounds the width and height parameters
+const maxSide = 1000
+
+// Params are the tunable values for this puzzle.
+var Params = []solutions.Param{
+	{Name: "width", Default: 25, Usage: "image width in pixels", Min: 1, Max: maxSide},
+	{Name: "height", Default: 6, Usage: "image height in pixels", Min: 1, Max: maxSide},
+}
 
 const (
 	black uint8 = iota
@@ -26,11 +34,19 @@ var colorMap = map[uint8]rune{
 	transparent: ' ',
 }
 
-type row [width]uint8
-type layer [height]row
+type row []uint8
+type layer []row
 type image []layer
 
-func (r *row) String() string {
+func newLayer(width, height int) layer {
+	l := make(layer, height)
+	for y := range l {
+		l[y] = make(row, width)
+	}
+	return l
+}
+
+func (r row) String() string {
 	builder := make([]rune, len(r))
 	for x := range r {
 		builder[x] = colorMap[r[x]]
@@ -38,7 +54,7 @@ func (r *row) String() string {
 	return string(builder)
 }
 
-func (l *layer) String() string {
+func (l layer) String() string {
 	rows := make([]string, len(l))
 	for y := range l {
 		rows[y] = l[y].String()
@@ -49,7 +65,7 @@ func (l *layer) String() string {
 // mergeDown merges l onto l2, replacing any transparent pixels in l with
 // non-transparent ones in the same location of l2.
 // Only l is mutated by this function.
-func (l *layer) mergeDown(l2 *layer) {
+func (l layer) mergeDown(l2 layer) {
 	for y := range l {
 		for x := range l[y] {
 			if l[y][x] == transparent && l2[y][x] != transparent {
@@ -80,8 +96,8 @@ func part1(im image) uint {
 	return minZeroCount[1] * minZeroCount[2]
 }
 
-func part2(im image) (combined *layer) {
-	combined = &layer{}
+func part2(im image, width, height int) (combined layer) {
+	combined = newLayer(width, height)
 	for y := range combined {
 		for x := range combined[y] {
 			combined[y][x] = transparent
@@ -89,18 +105,33 @@ func part2(im image) (combined *layer) {
 	}
 
 	for li := range im {
-		combined.mergeDown(&im[li])
+		combined.mergeDown(im[li])
 	}
 	return
 }
 
 // Solve provides the day 8 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
+	width, height := env.Param("width"), env.Param("height")
+	layerSize := width * height
 	numLayers := len(input) / layerSize
+	if numLayers == 0 {
+		return nil, fmt.Errorf("the image has %d pixels, not enough for a %dx%d layer", len(input), width, height)
+	}
 	im := make(image, numLayers)
-	for i, b := range []byte(input) {
+	for li := range im {
+		im[li] = newLayer(width, height)
+	}
+	for i, b := range []byte(input[:numLayers*layerSize]) {
 		// indexes in order: layer, y, x.
 		im[i/layerSize][(i%layerSize)/width][i%width] = b - asciiDigitDiff
 	}
-	return &solutions.Solution{Part1: part1(im), Part2: part2(im)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = part1(im)
+	}
+	if env.Wants(2) {
+		solution.Part2 = part2(im, width, height)
+	}
+	return solution, nil
 }
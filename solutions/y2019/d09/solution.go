@@ -1,41 +1,58 @@
 package d09
 
 import (
-	"fmt"
-
+	"github.com/jzimbel/adventofcode-go/diag"
 	"github.com/jzimbel/adventofcode-go/solutions"
 	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
 )
 
-func part1(initMem interpreter.Program) (result int) {
+// Title is the name of the puzzle.
+const Title = "Sensor Boost"
+
+func part1(initMem interpreter.Program, d *diag.Sink) (result int, err error) {
 	input := func() int {
 		return 1
 	}
 	output := func(n int) {
 		result = n
-		fmt.Println(n)
+		d.Infof("BOOST output: %d", n)
 	}
 
-	interpreter.New(initMem, input, output).Run()
+	_, err = interpreter.New(initMem, input, output).Run()
 	return
 }
 
-func part2(initMem interpreter.Program) (result int) {
+func part2(initMem interpreter.Program, d *diag.Sink) (result int, err error) {
 	input := func() int {
 		return 2
 	}
 	output := func(n int) {
 		result = n
-		fmt.Println(n)
+		d.Infof("BOOST output: %d", n)
 	}
 
-	interpreter.New(initMem, input, output).Run()
+	_, err = interpreter.New(initMem, input, output).Run()
 	return
 }
 
 // Solve provides the day 9 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	initMem := interpreter.ParseMem(input)
 
-	return &solutions.Solution{Part1: part1(initMem), Part2: part2(initMem)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		answer1, err := part1(initMem, env.Diag)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part1 = answer1
+	}
+	if env.Wants(2) {
+		answer2, err := part2(initMem, env.Diag)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part2 = answer2
+	}
+	return solution, nil
 }
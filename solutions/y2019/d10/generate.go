@@ -0,0 +1,39 @@
+package d10
+
+import (
+	"math/rand"
+	"strings"
+)
+
+const (
+	// width and height of a real puzzle input
+	realSize = 24
+	// fraction of positions that have an asteroid in a real puzzle input, give or take
+	density = 0.5
+)
+
+// Generate makes a random size by size asteroid field with at least two asteroids.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	if size < 2 {
+		size = 2
+	}
+
+	rows := make([]string, size)
+	for y := range rows {
+		row := make([]byte, size)
+		for x := range row {
+			if rng.Float64() < density {
+				row[x] = '#'
+			} else {
+				row[x] = '.'
+			}
+		}
+		rows[y] = string(row)
+	}
+	// make sure part 1 has a line of sight to look along
+	rows[0] = "##" + rows[0][2:]
+	return strings.Join(rows, "\n")
+}
@@ -8,11 +8,18 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions"
 )
 
-const (
-	// width and height of my puzzle input, used for some slight optimizations
-	width  = 24
-	height = 24
-)
+// Title is the name of the puzzle.
+const Title = "Monitoring Station"
+
+// maxSide bounds the width and height parameters, which size the map of asteroids before it's filled in
+const maxSide = 1000
+
+// Params are the tunable values for this puzzle.
+var Params = []solutions.Param{
+	{Name: "width", Default: 24, Usage: "expected width of the asteroid map, used for some slight optimizations", Min: 1, Max: maxSide},
+	{Name: "height", Default: 24, Usage: "expected height of the asteroid map, used for some slight optimizations", Min: 1, Max: maxSide},
+	{Name: "bet", Default: 200, Usage: "which vaporized asteroid part 2 reports", Min: 1, Max: math.MaxInt},
+}
 
 var epsilon float64
 
@@ -129,7 +136,7 @@ func clockwiseAngleFromUp(p1, p2 *point) (θ float64) {
 	return
 }
 
-func part2(g grid, optimalPoint point) int {
+func part2(g grid, optimalPoint point, bet int) int {
 	// record angle and distance from center of each asteroid in a sorted slice of struct {rad float64; dist float64}
 	rp := make(rPoints, 0, len(g))
 	for p := range g {
@@ -149,7 +156,7 @@ func part2(g grid, optimalPoint point) int {
 				nextRp = append(nextRp, rp[i])
 			} else {
 				vaporizedCount++
-				if vaporizedCount == 200 {
+				if vaporizedCount == bet {
 					return rp[i].orig.x*100 + rp[i].orig.y
 				}
 				remove = append(remove, &rp[i].orig)
@@ -161,13 +168,13 @@ func part2(g grid, optimalPoint point) int {
 		}
 		rp = nextRp
 	}
-	// unreachable as long as there are at least 200 asteroids on the grid
+	// unreachable as long as there are at least bet asteroids on the grid
 	return 0
 }
 
 // Solve provides the day 10 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
-	g := make(grid, width*height)
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
+	g := make(grid, env.Param("width")*env.Param("height"))
 	rows := strings.Split(input, "\n")
 	for y := range rows {
 		for x := range rows[y] {
@@ -176,12 +183,20 @@ func Solve(input string) (*solutions.Solution, error) {
 			}
 		}
 	}
+	// part 2 needs the station's location from part 1
 	maxVisibleCount, optimalPoint := part1(g)
 
-	return &solutions.Solution{Part1: maxVisibleCount, Part2: part2(g, optimalPoint)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = maxVisibleCount
+	}
+	if env.Wants(2) {
+		solution.Part2 = part2(g, optimalPoint, env.Param("bet"))
+	}
+	return solution, nil
 }
 
 func init() {
 	epsilon = math.Nextafter(1, 2) - 1
-	gcdCache = make(map[[2]int]int, width*height)
+	gcdCache = make(map[[2]int]int)
 }
@@ -7,6 +7,9 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
 )
 
+// Title is the name of the puzzle.
+const Title = "Space Police"
+
 const (
 	outputTypeColor = iota
 	outputTypeTurn
@@ -99,7 +102,7 @@ func (g grid) String() string {
 	return strings.Join(builder, "\n")
 }
 
-func run(initMem interpreter.Program, startColor int) (paintedCount int, g grid) {
+func run(initMem interpreter.Program, startColor int) (paintedCount int, g grid, err error) {
 	var outputType, dirIndex int
 	g = make(grid)
 	if startColor == white {
@@ -129,22 +132,37 @@ func run(initMem interpreter.Program, startColor int) (paintedCount int, g grid)
 		outputType = (outputType + 1) % 2
 	}
 
-	interpreter.New(initMem, input, output).Run()
+	_, err = interpreter.New(initMem, input, output).Run()
 	return
 }
 
-func part1(initMem interpreter.Program) (paintedCount int) {
-	paintedCount, _ = run(initMem, black)
+func part1(initMem interpreter.Program) (paintedCount int, err error) {
+	paintedCount, _, err = run(initMem, black)
 	return
 }
 
-func part2(initMem interpreter.Program) (g grid) {
-	_, g = run(initMem, white)
+func part2(initMem interpreter.Program) (g grid, err error) {
+	_, g, err = run(initMem, white)
 	return
 }
 
 // Solve provides the day 11 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	initMem := interpreter.ParseMem(input)
-	return &solutions.Solution{Part1: part1(initMem), Part2: part2(initMem)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		answer1, err := part1(initMem)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part1 = answer1
+	}
+	if env.Wants(2) {
+		answer2, err := part2(initMem)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part2 = answer2
+	}
+	return solution, nil
 }
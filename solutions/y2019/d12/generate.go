@@ -0,0 +1,75 @@
+package d12
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+const (
+	// number of moons in a real puzzle input
+	moonCount = 4
+	// bound on the coordinates in a real puzzle input, give or take
+	realSize = 15
+	// longest single-axis period in a real puzzle input, give or take.
+	// Random positions can take far longer to repeat, so they're rerolled.
+	maxPeriod = 300000
+)
+
+// axisRepeats reports whether moons starting at rest at the given positions along one axis
+// return to their starting state within limit steps.
+func axisRepeats(start [moonCount]int, limit int) bool {
+	pos, vel := start, [moonCount]int{}
+	for step := 1; step <= limit; step++ {
+		for i := range pos {
+			for j := range pos {
+				switch {
+				case pos[i] < pos[j]:
+					vel[i]++
+				case pos[i] > pos[j]:
+					vel[i]--
+				}
+			}
+		}
+		for i := range pos {
+			pos[i] += vel[i]
+		}
+		if pos == start && vel == [moonCount]int{} {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate makes a random set of moon positions with coordinates in [-size, size].
+// The moons always return to their starting state within a realistic number of steps.
+func Generate(rng *rand.Rand, size int) string {
+	if size == 0 {
+		size = realSize
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	var moons [moonCount]point
+	for axis := 0; axis < axisCount; axis++ {
+		for {
+			var start [moonCount]int
+			for i := range start {
+				start[i] = rng.Intn(2*size+1) - size
+			}
+			if axisRepeats(start, maxPeriod) {
+				for i := range moons {
+					moons[i][axis] = start[i]
+				}
+				break
+			}
+		}
+	}
+
+	lines := make([]string, moonCount)
+	for i, p := range moons {
+		lines[i] = fmt.Sprintf("<x=%d, y=%d, z=%d>", p[0], p[1], p[2])
+	}
+	return strings.Join(lines, "\n")
+}
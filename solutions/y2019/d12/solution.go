@@ -10,10 +10,15 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions"
 )
 
-const (
-	axisCount = 3
-	steps     = 1000
-)
+// Title is the name of the puzzle.
+const Title = "The N-Body Problem"
+
+const axisCount = 3
+
+// Params are the tunable values for this puzzle.
+var Params = []solutions.Param{
+	{Name: "steps", Default: 1000, Usage: "number of time steps to simulate for part 1", Min: 0, Max: math.MaxInt},
+}
 
 type point [3]int
 type vec struct {
@@ -89,7 +94,7 @@ func (a axis) equals(a2 axis) (eq bool) {
 	return
 }
 
-func (a axis) simulate() {
+func (a axis) simulate(steps int) {
 	for j := 0; j < steps; j++ {
 		a.gravitate()
 		a.move()
@@ -115,14 +120,14 @@ func (a axis) findRepeat() (count int) {
 	return
 }
 
-func (axs *axisSystem) simulate() {
+func (axs *axisSystem) simulate(steps int) {
 	wg := sync.WaitGroup{}
 	defer wg.Wait()
 	wg.Add(axisCount)
 	for i := range axs {
 		go func(icpy int) {
 			defer wg.Done()
-			axs[icpy].simulate()
+			axs[icpy].simulate(steps)
 		}(i)
 	}
 }
@@ -202,11 +207,11 @@ func lcm(a, b uint64, integers ...uint64) uint64 {
 	return result
 }
 
-func part1(initS system) int {
+func part1(initS system, steps int) int {
 	s := make(system, len(initS))
 	copy(s, initS)
 	axs := newAxisSystem(s)
-	axs.simulate()
+	axs.simulate(steps)
 	return s.energy()
 }
 
@@ -218,7 +223,14 @@ func part2(initS system) uint64 {
 }
 
 // Solve provides the day 12 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	s := parse(input)
-	return &solutions.Solution{Part1: part1(s), Part2: part2(s)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		solution.Part1 = part1(s, env.Param("steps"))
+	}
+	if env.Wants(2) {
+		solution.Part2 = part2(s)
+	}
+	return solution, nil
 }
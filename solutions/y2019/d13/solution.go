@@ -7,11 +7,8 @@ import (
 	"github.com/jzimbel/adventofcode-go/solutions/y2019/interpreter"
 )
 
-const (
-	outputTypeX = iota
-	outputTypeY
-	outputTypeDraw
-)
+// Title is the name of the puzzle.
+const Title = "Care Package"
 
 const (
 	_ = iota
@@ -28,74 +25,105 @@ type point struct {
 
 type grid map[point]int
 
-func part1(initMem interpreter.Program) int {
-	g := make(grid)
-	var x, y, outputType int
+// game is the state of the arcade cabinet, as drawn by its program.
+type game struct {
+	g       grid
+	score   int
+	ballX   int
+	paddleX int
+}
+
+// draw handles one x, y, tile instruction from the program. The position -1, 0 shows the score instead of a tile.
+func (gm *game) draw(x, y, tile int) {
+	if x == -1 && y == 0 {
+		gm.score = tile
+		return
+	}
+	gm.g[point{x, y}] = tile
+	switch tile {
+	case ball:
+		gm.ballX = x
+	case paddle:
+		gm.paddleX = x
+	}
+}
 
-	output := func(n int) {
-		switch outputType {
-		case outputTypeX:
-			x = n
-		case outputTypeY:
-			y = n
-		case outputTypeDraw:
-			g[point{x, y}] = n
+// joystick tilts toward the ball, so the paddle follows it.
+func (gm *game) joystick() int {
+	diff := gm.ballX - gm.paddleX
+	if diff == 0 {
+		return diff
+	}
+	return diff / int(math.Abs(float64(diff)))
+}
+
+// play runs the arcade program to the end, moving the joystick whenever it asks for input.
+func play(m *interpreter.Interpreter) (*game, error) {
+	gm := &game{g: make(grid)}
+	var instr []int
+	for {
+		e, err := m.RunUntilEvent()
+		if err != nil {
+			return nil, err
+		}
+		switch e {
+		case interpreter.Halted:
+			return gm, nil
+		case interpreter.NeedInput:
+			m.PushInput(gm.joystick())
+		case interpreter.Output:
+			n, _ := m.PopOutput()
+			// outputs come in threes
+			if instr = append(instr, n); len(instr) == 3 {
+				gm.draw(instr[0], instr[1], instr[2])
+				instr = instr[:0]
+			}
 		}
-		outputType = (outputType + 1) % 3
 	}
+}
 
-	interpreter.New(initMem, nil, output).Run()
+func part1(initMem interpreter.Program) (int, error) {
+	gm, err := play(interpreter.New(initMem, nil, nil))
+	if err != nil {
+		return 0, err
+	}
 
 	var blockCount int
-	for _, tile := range g {
+	for _, tile := range gm.g {
 		if tile == block {
 			blockCount++
 		}
 	}
-	return blockCount
+	return blockCount, nil
 }
 
-func part2(initMem interpreter.Program) int {
+func part2(initMem interpreter.Program) (int, error) {
+	// insert quarters to play for free
 	initMem[0] = 2
-	g := make(grid)
-	var x, y, outputType, ballX, paddleX, score int
-
-	input := func() int {
-		diff := ballX - paddleX
-		if diff == 0 {
-			return diff
-		}
-		return diff / int(math.Abs(float64(diff)))
-	}
-
-	output := func(n int) {
-		switch outputType {
-		case outputTypeX:
-			x = n
-		case outputTypeY:
-			y = n
-		case outputTypeDraw:
-			if x == -1 && y == 0 {
-				score = n
-			} else {
-				g[point{x, y}] = n
-				switch n {
-				case ball:
-					ballX = x
-				case paddle:
-					paddleX = x
-				}
-			}
-		}
-		outputType = (outputType + 1) % 3
+	gm, err := play(interpreter.New(initMem, nil, nil))
+	if err != nil {
+		return 0, err
 	}
-
-	interpreter.New(initMem, input, output).Run()
-	return score
+	return gm.score, nil
 }
 
 // Solve provides the day 13 puzzle solution.
-func Solve(input string) (*solutions.Solution, error) {
+func Solve(input string, env *solutions.Env) (*solutions.Solution, error) {
 	initMem := interpreter.ParseMem(input)
-	return &solutions.Solution{Part1: part1(initMem), Part2: part2(initMem)}, nil
+	solution := &solutions.Solution{}
+	if env.Wants(1) {
+		answer1, err := part1(initMem)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part1 = answer1
+	}
+	if env.Wants(2) {
+		answer2, err := part2(initMem)
+		if err != nil {
+			return nil, err
+		}
+		solution.Part2 = answer2
+	}
+	return solution, nil
 }
@@ -1,3 +1,5 @@
+// Code generated by solutioninit/gen; DO NOT EDIT.
+
 package y2019
 
 import (
@@ -18,18 +20,18 @@ import (
 )
 
 func init() {
-	r, y := &solutions.Registry, 2019
-	r.Register(y, 1, d01.Solve)
-	r.Register(y, 2, d02.Solve)
-	r.Register(y, 3, d03.Solve)
-	r.Register(y, 4, d04.Solve)
-	r.Register(y, 5, d05.Solve)
-	r.Register(y, 6, d06.Solve)
-	r.Register(y, 7, d07.Solve)
-	r.Register(y, 8, d08.Solve)
-	r.Register(y, 9, d09.Solve)
-	r.Register(y, 10, d10.Solve)
-	r.Register(y, 11, d11.Solve)
-	r.Register(y, 12, d12.Solve)
-	r.Register(y, 13, d13.Solve)
+	r, y := solutions.Registry, 2019
+	r.Register(y, 1, d01.Solve, solutions.WithTitle(d01.Title))
+	r.Register(y, 2, d02.Solve, solutions.WithTitle(d02.Title), solutions.WithParams(d02.Params...), solutions.WithGenerator(d02.Generate))
+	r.Register(y, 3, d03.Solve, solutions.WithTitle(d03.Title), solutions.WithGenerator(d03.Generate), solutions.WithImpl("segments", d03.SolveSegments))
+	r.Register(y, 4, d04.Solve, solutions.WithTitle(d04.Title), solutions.WithGenerator(d04.Generate), solutions.WithImpl("combinatorics", d04.SolveCombinatorics))
+	r.Register(y, 5, d05.Solve, solutions.WithTitle(d05.Title))
+	r.Register(y, 6, d06.Solve, solutions.WithTitle(d06.Title), solutions.WithGenerator(d06.Generate))
+	r.Register(y, 7, d07.Solve, solutions.WithTitle(d07.Title), solutions.WithParams(d07.Params...), solutions.WithGenerator(d07.Generate))
+	r.Register(y, 8, d08.Solve, solutions.WithTitle(d08.Title), solutions.WithParams(d08.Params...))
+	r.Register(y, 9, d09.Solve, solutions.WithTitle(d09.Title))
+	r.Register(y, 10, d10.Solve, solutions.WithTitle(d10.Title), solutions.WithParams(d10.Params...), solutions.WithGenerator(d10.Generate))
+	r.Register(y, 11, d11.Solve, solutions.WithTitle(d11.Title))
+	r.Register(y, 12, d12.Solve, solutions.WithTitle(d12.Title), solutions.WithParams(d12.Params...), solutions.WithGenerator(d12.Generate))
+	r.Register(y, 13, d13.Solve, solutions.WithTitle(d13.Title))
 }
@@ -0,0 +1,408 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SyntaxError is a mistake in assembly, at a line and column counted from 1.
+type SyntaxError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// opcodes of the mnemonics, for the assembler
+var opcodes = func() map[string]op {
+	m := make(map[string]op, len(mnemonics))
+	for code, name := range mnemonics {
+		m[name] = code
+	}
+	return m
+}()
+
+// dataDirective emits its operands as they are, rather than as an instruction.
+const dataDirective = "DATA"
+
+// token is a piece of a line of assembly: a name, a number, a string literal or a punctuation mark.
+type token struct {
+	kind rune // 'a' for a name, '0' for a number, '"' for a string, and otherwise the mark itself
+	text string
+	col  int
+}
+
+// tokenize splits a line of assembly into tokens, dropping the comment at its end.
+func tokenize(line string, lineNo int) ([]token, error) {
+	var tokens []token
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		start := i
+		switch {
+		case c == ';':
+			return tokens, nil
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{'a', string(runes[start:i]), start + 1})
+		case unicode.IsDigit(c):
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{'0', string(runes[start:i]), start + 1})
+		case c == '"':
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(runes) {
+				return nil, &SyntaxError{lineNo, start + 1, "string isn't closed"}
+			}
+			i++
+			tokens = append(tokens, token{'"', string(runes[start:i]), start + 1})
+		case strings.ContainsRune(":,[]#+-*()", c):
+			i++
+			tokens = append(tokens, token{c, string(c), start + 1})
+		default:
+			return nil, &SyntaxError{lineNo, start + 1, fmt.Sprintf("unexpected %q", c)}
+		}
+	}
+	return tokens, nil
+}
+
+// expr is an arithmetic expression of numbers and labels.
+type expr struct {
+	op          rune // '0' for a number, 'a' for a label, or '+', '-' or '*'
+	num         int
+	name        string
+	left, right *expr
+	col         int
+}
+
+func (e *expr) eval(labels map[string]int, lineNo int) (int, error) {
+	switch e.op {
+	case '0':
+		return e.num, nil
+	case 'a':
+		v, ok := labels[e.name]
+		if !ok {
+			return 0, &SyntaxError{lineNo, e.col, fmt.Sprintf("undefined label %q", e.name)}
+		}
+		return v, nil
+	}
+	l, err := e.left.eval(labels, lineNo)
+	if err != nil {
+		return 0, err
+	}
+	r, err := e.right.eval(labels, lineNo)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	}
+	return l * r, nil
+}
+
+// operand is a parameter of an instruction, or a value of a DATA directive.
+type operand struct {
+	m     mode
+	value *expr
+}
+
+// parser reads the tokens of one line of assembly.
+type parser struct {
+	tokens []token
+	pos    int
+	lineNo int
+	// col is just past the end of the line, for errors about things that are missing
+	col int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{kind: 0, col: p.col}
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{p.lineNo, t.col, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(kind rune) error {
+	if t := p.next(); t.kind != kind {
+		return p.errorf(t, "expected %q", kind)
+	}
+	return nil
+}
+
+// parseExpr reads a sum of products.
+func (p *parser) parseExpr() (*expr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseSum(left)
+}
+
+// parseSum reads any terms added to or subtracted from left.
+func (p *parser) parseSum(left *expr) (*expr, error) {
+	for p.peek().kind == '+' || p.peek().kind == '-' {
+		t := p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &expr{op: t.kind, left: left, right: right, col: t.col}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (*expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == '*' {
+		t := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &expr{op: '*', left: left, right: right, col: t.col}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (*expr, error) {
+	t := p.next()
+	switch t.kind {
+	case '-':
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &expr{op: '-', left: &expr{op: '0'}, right: e, col: t.col}, nil
+	case '0':
+		n, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, p.errorf(t, "number %s is out of range", t.text)
+		}
+		return &expr{op: '0', num: n, col: t.col}, nil
+	case 'a':
+		return &expr{op: 'a', name: t.text, col: t.col}, nil
+	case '(':
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(')')
+	}
+	return nil, p.errorf(t, "expected a number, a label or (")
+}
+
+// parseOperand reads [x], #x or rb+x.
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	switch {
+	case t.kind == '[':
+		p.next()
+		e, err := p.parseExpr()
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{mPosition, e}, p.expect(']')
+	case t.kind == '#':
+		p.next()
+		e, err := p.parseExpr()
+		return operand{mImmediate, e}, err
+	case t.kind == 'a' && strings.EqualFold(t.text, "rb"):
+		p.next()
+		e, err := p.parseSum(&expr{op: '0', col: t.col})
+		return operand{mRelative, e}, err
+	}
+	return operand{}, p.errorf(t, "expected a parameter: [address], #value or rb+offset")
+}
+
+// statement is an instruction or DATA directive, as parsed in the first pass.
+type statement struct {
+	lineNo int
+	col    int
+	// directive is DATA, or else this is an instruction with this opcode
+	directive string
+	code      op
+	operands  []operand
+	// strings holds the characters of string literals given to DATA, by position among its operands
+	strings map[int]string
+}
+
+// size is the number of memory cells the statement takes.
+func (s *statement) size() int {
+	if s.directive == "" {
+		return 1 + len(s.operands)
+	}
+	n := len(s.operands)
+	for _, str := range s.strings {
+		n += utf8.RuneCountInString(str) - 1
+	}
+	return n
+}
+
+// Assemble turns assembly into a program, in the syntax that Disassemble writes: an instruction per line, made of
+// a mnemonic like ADD or JNZ and its parameters, written [x] in position mode, #x in immediate mode and
+// rb+x in relative mode. Lines can start with labels like loop:, and comments start with ;.
+//
+// Anywhere a number goes, an expression of numbers and labels with +, -, * and parentheses can go instead.
+// DATA puts values into the program as they are, and also takes strings like "hi\n", which it puts in as
+// one character code per cell.
+func Assemble(src string) (Program, error) {
+	labels := make(map[string]int)
+	var stmts []statement
+	addr := 0
+	for i, line := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		tokens, err := tokenize(line, lineNo)
+		if err != nil {
+			return nil, err
+		}
+		p := &parser{tokens: tokens, lineNo: lineNo, col: len([]rune(line)) + 1}
+		for len(p.tokens) > p.pos+1 && p.peek().kind == 'a' && p.tokens[p.pos+1].kind == ':' {
+			t := p.next()
+			p.next()
+			if strings.EqualFold(t.text, "rb") {
+				return nil, p.errorf(t, "rb is the relative base, and can't be a label")
+			}
+			if _, ok := labels[t.text]; ok {
+				return nil, p.errorf(t, "label %q is already defined", t.text)
+			}
+			labels[t.text] = addr
+		}
+		if p.pos == len(p.tokens) {
+			continue
+		}
+
+		t := p.next()
+		if t.kind != 'a' {
+			return nil, p.errorf(t, "expected a mnemonic or %s", dataDirective)
+		}
+		s := statement{lineNo: lineNo, col: t.col}
+		name := strings.ToUpper(t.text)
+		if name == dataDirective {
+			s.directive = name
+		} else if s.code, err = lookupOpcode(name); err != nil {
+			return nil, p.errorf(t, "%v", err)
+		}
+
+		for p.pos < len(p.tokens) {
+			if len(s.operands) > 0 || s.strings != nil {
+				if err := p.expect(','); err != nil {
+					return nil, err
+				}
+			}
+			if s.directive != "" {
+				if t := p.peek(); t.kind == '"' {
+					p.next()
+					str, err := strconv.Unquote(t.text)
+					if err != nil {
+						return nil, p.errorf(t, "bad string: %v", err)
+					}
+					if s.strings == nil {
+						s.strings = make(map[int]string)
+					}
+					s.strings[len(s.operands)] = str
+					s.operands = append(s.operands, operand{})
+					continue
+				}
+				e, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				s.operands = append(s.operands, operand{mImmediate, e})
+				continue
+			}
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			s.operands = append(s.operands, o)
+		}
+
+		if s.directive == "" {
+			if want := int(paramCount(s.code)); len(s.operands) != want {
+				return nil, p.errorf(t, "%s takes %d parameters, not %d", name, want, len(s.operands))
+			}
+			if w, ok := writeParam[s.code]; ok && s.operands[w].m == mImmediate {
+				return nil, p.errorf(t, "%s writes to its parameter %d, so it can't be immediate", name, w+1)
+			}
+		}
+		stmts = append(stmts, s)
+		addr += s.size()
+	}
+
+	prog := make(Program, 0, addr)
+	for i := range stmts {
+		s := &stmts[i]
+		if s.directive == "" {
+			modes := make([]mode, len(s.operands))
+			for j, o := range s.operands {
+				modes[j] = o.m
+			}
+			prog = append(prog, encode(s.code, modes))
+		}
+		for j, o := range s.operands {
+			if str, ok := s.strings[j]; ok {
+				for _, c := range str {
+					prog = append(prog, int(c))
+				}
+				continue
+			}
+			v, err := o.value.eval(labels, s.lineNo)
+			if err != nil {
+				return nil, err
+			}
+			prog = append(prog, v)
+		}
+	}
+	return prog, nil
+}
+
+func lookupOpcode(name string) (op, error) {
+	code, ok := opcodes[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown mnemonic %q", name)
+	}
+	return code, nil
+}
+
+// String formats a program the way puzzle inputs are written, and the way ParseMem reads them.
+func (p Program) String() string {
+	numbers := make([]string, len(p))
+	for i, n := range p {
+		numbers[i] = strconv.Itoa(n)
+	}
+	return strings.Join(numbers, ",")
+}
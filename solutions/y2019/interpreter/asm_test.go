@@ -0,0 +1,70 @@
+package interpreter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisassembleRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		program Program
+	}{
+		{"noun and verb", nounVerbProgram},
+		{"feedback loop", feedbackProgram},
+		{"polling", poll},
+		{"quine", quine},
+		// the day 5 example that compares its input to 8
+		{"compare to 8", ParseMem("3,21,1008,21,8,20,1005,20,22,107,8,21,20,1006,20,31,1106,0,36,98,0,0,1002,21,125,20,4,20,1105,1,46,104,999,1105,1,46,1101,1000,1,20,4,20,1105,1,46,98,99")},
+		{"data that looks like code", Program{1105, 1, 7, 1, 2, 3, 4, 99}},
+		{"negative values", Program{109, -3, 21101, -1, -2, 5, 99}},
+		{"empty", Program{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Disassemble(tt.program)
+			got, err := Assemble(src)
+			if err != nil {
+				t.Fatalf("assembling the disassembly: %v\n%s", err, src)
+			}
+			if !reflect.DeepEqual(got, tt.program) {
+				t.Errorf("assembled %v, want %v\n%s", got, tt.program, src)
+			}
+		})
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		line, col int
+		msg       string
+	}{
+		{"unexpected character", "HLT\nADD [1], [2], @3", 2, 15, "unexpected"},
+		{"unclosed string", `DATA "hi`, 1, 6, "isn't closed"},
+		{"undefined label", "JZ #0, #end", 1, 9, "undefined label"},
+		{"label defined twice", "a: HLT\na: HLT", 2, 1, "already defined"},
+		{"rb as a label", "rb: HLT", 1, 1, "relative base"},
+		{"unknown mnemonic", "; comment\n  FOO [1]", 2, 3, "unknown mnemonic"},
+		{"too few parameters", "ADD [1], [2]", 1, 1, "takes 3 parameters"},
+		{"immediate write", "\n\nADD [1], [2], #3", 3, 1, "can't be immediate"},
+		{"missing comma", "ADD [1] [2], [3]", 1, 9, "expected ','"},
+		{"missing parameter", "ADD [1],", 1, 9, "expected a parameter"},
+		{"number out of range", "DATA 99999999999999999999", 1, 6, "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Assemble(tt.src)
+			var e *SyntaxError
+			if !errors.As(err, &e) {
+				t.Fatalf("got error %v, want a *SyntaxError", err)
+			}
+			if e.Line != tt.line || e.Col != tt.col || !strings.Contains(e.Msg, tt.msg) {
+				t.Errorf("got %v, want line %d, column %d: ...%s...", e, tt.line, tt.col, tt.msg)
+			}
+		})
+	}
+}
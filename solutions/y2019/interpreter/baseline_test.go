@@ -0,0 +1,329 @@
+package interpreter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jzimbel/adventofcode-go/solutions/common"
+)
+
+// This is the interpreter as it was before memory became a slice and instructions were decoded once and cached:
+// memory is a map, every instruction is decoded afresh with common.GetDigits, and arguments are passed in a
+// slice to a function looked up in a map. It's kept only to benchmark against, so that the speedup can be
+// measured again on any machine:
+//
+//	go test -run ^$ -bench 'NounVerbSearch|FeedbackLoop' ./solutions/y2019/interpreter
+//
+// Errors are reported bare rather than as *Error, and there's no observer.
+
+type baselineProgram map[uint]int
+
+type baselineInterpreter struct {
+	mem     baselineProgram
+	ipt     uint
+	rpt     int
+	inputs  []int
+	outputs []int
+}
+
+// pairs a function that executes an instruction with its arity
+// and indices of args (if any) that are used for writing values to memory
+type baselineProcedure struct {
+	f         func(i *baselineInterpreter, args ...int) (skipIncrement bool, err error)
+	arity     uint
+	writeArgs baselineSet
+}
+
+type baselineOpDesc struct {
+	code op
+	proc *baselineProcedure
+	m    [3]mode
+}
+
+type baselineSet map[uint]struct{}
+
+func makeBaselineSet(values ...uint) (s baselineSet) {
+	s = make(baselineSet, len(values))
+	for _, value := range values {
+		s[value] = struct{}{}
+	}
+	return
+}
+
+func (s baselineSet) has(value uint) (ok bool) {
+	_, ok = s[value]
+	return
+}
+
+func newBaseline(initMem Program, noun, verb int, setNounVerb bool) *baselineInterpreter {
+	mem := make(baselineProgram, len(initMem))
+	for i, n := range initMem {
+		mem[uint(i)] = n
+	}
+	if setNounVerb {
+		mem[1] = noun
+		mem[2] = verb
+	}
+	return &baselineInterpreter{mem: mem}
+}
+
+func (i *baselineInterpreter) run() (int, error) {
+	for {
+		e, err := i.runUntilEvent()
+		if err != nil {
+			return 0, err
+		}
+		switch e {
+		case Halted:
+			return i.get(0), nil
+		case NeedInput:
+			return 0, ErrNoInput
+		}
+	}
+}
+
+func (i *baselineInterpreter) runUntilEvent() (Event, error) {
+	for {
+		o := baselineGetOpDesc(i.get(i.ipt))
+		if o.proc == nil {
+			return Halted, ErrUnknownOpcode
+		}
+		if o.proc.f == nil {
+			return Halted, nil
+		}
+		if o.code == oIn && len(i.inputs) == 0 {
+			return NeedInput, nil
+		}
+
+		args := make([]int, o.proc.arity)
+		var j uint
+		for j = 0; j < o.proc.arity; j++ {
+			var err error
+			if args[j], err = i.getParam(j+1, o.m[j], o.proc.writeArgs.has(j)); err != nil {
+				return Halted, err
+			}
+		}
+
+		skipIncrement, err := o.proc.f(i, args...)
+		if err != nil {
+			return Halted, err
+		}
+		if !skipIncrement {
+			i.ipt += o.proc.arity + 1
+		}
+		if o.code == oOut {
+			return Output, nil
+		}
+	}
+}
+
+func (i *baselineInterpreter) get(addr uint) int {
+	return i.mem[addr]
+}
+
+func (i *baselineInterpreter) set(addr uint, val int) {
+	i.mem[addr] = val
+}
+
+func (i *baselineInterpreter) getParam(offset uint, m mode, isWriteArg bool) (int, error) {
+	var addr int
+	switch m {
+	case mPosition:
+		addr = i.get(i.ipt + offset)
+	case mImmediate:
+		if isWriteArg {
+			return 0, ErrWriteImmediate
+		}
+		return i.get(i.ipt + offset), nil
+	case mRelative:
+		addr = i.rpt + i.get(i.ipt+offset)
+	default:
+		return 0, ErrBadMode
+	}
+	if addr < 0 {
+		return 0, ErrNegativeAddress
+	}
+	if isWriteArg {
+		return addr, nil
+	}
+	return i.get(uint(addr)), nil
+}
+
+func baselineGetOpDesc(o int) *baselineOpDesc {
+	code := op(common.GetDigits(o, 0, 2))
+	return &baselineOpDesc{
+		code: code,
+		proc: baselineProcedures[code],
+		m: [...]mode{
+			mode(common.GetDigit(o, 2)),
+			mode(common.GetDigit(o, 3)),
+			mode(common.GetDigit(o, 4)),
+		},
+	}
+}
+
+func baselineJump(i *baselineInterpreter, target int) (bool, error) {
+	if target < 0 {
+		return false, ErrNegativeAddress
+	}
+	i.ipt = uint(target)
+	return true, nil
+}
+
+func baselineCompare(i *baselineInterpreter, addr int, holds bool) {
+	if holds {
+		i.set(uint(addr), 1)
+	} else {
+		i.set(uint(addr), 0)
+	}
+}
+
+var baselineProcedures = map[op]*baselineProcedure{
+	oAdd: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			i.set(uint(args[2]), args[0]+args[1])
+			return false, nil
+		},
+		arity:     3,
+		writeArgs: makeBaselineSet(2),
+	},
+	oMul: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			i.set(uint(args[2]), args[0]*args[1])
+			return false, nil
+		},
+		arity:     3,
+		writeArgs: makeBaselineSet(2),
+	},
+	oIn: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			var n int
+			n, i.inputs = i.inputs[0], i.inputs[1:]
+			i.set(uint(args[0]), n)
+			return false, nil
+		},
+		arity:     1,
+		writeArgs: makeBaselineSet(0),
+	},
+	oOut: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			i.outputs = append(i.outputs, args[0])
+			return false, nil
+		},
+		arity:     1,
+		writeArgs: makeBaselineSet(),
+	},
+	oJumpIfNonZero: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			if args[0] != 0 {
+				return baselineJump(i, args[1])
+			}
+			return false, nil
+		},
+		arity:     2,
+		writeArgs: makeBaselineSet(),
+	},
+	oJumpIfZero: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			if args[0] == 0 {
+				return baselineJump(i, args[1])
+			}
+			return false, nil
+		},
+		arity:     2,
+		writeArgs: makeBaselineSet(),
+	},
+	oLessThan: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			baselineCompare(i, args[2], args[0] < args[1])
+			return false, nil
+		},
+		arity:     3,
+		writeArgs: makeBaselineSet(2),
+	},
+	oEquals: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			baselineCompare(i, args[2], args[0] == args[1])
+			return false, nil
+		},
+		arity:     3,
+		writeArgs: makeBaselineSet(2),
+	},
+	oMoveRpt: {
+		f: func(i *baselineInterpreter, args ...int) (bool, error) {
+			i.rpt += args[0]
+			return false, nil
+		},
+		arity:     1,
+		writeArgs: makeBaselineSet(),
+	},
+	oHalt: {
+		arity:     0,
+		writeArgs: makeBaselineSet(),
+	},
+}
+
+// BenchmarkBaselineNounVerbSearch is BenchmarkNounVerbSearch on the baseline interpreter.
+func BenchmarkBaselineNounVerbSearch(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		for noun := 0; noun < 100; noun++ {
+			for verb := 0; verb < 100; verb++ {
+				if _, err := newBaseline(nounVerbProgram, noun, verb, true).run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkBaselineFeedbackLoop is BenchmarkFeedbackLoop on the baseline interpreter. There was no Network
+// before, so the amplifiers take turns as day 7 used to run them: each runs until it wants input, and its
+// outputs go to the next.
+func BenchmarkBaselineFeedbackLoop(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		amps := make([]*baselineInterpreter, 5)
+		for i := range amps {
+			amps[i] = newBaseline(feedbackProgram, 0, 0, false)
+			amps[i].inputs = append(amps[i].inputs, 9-i)
+		}
+		amps[0].inputs = append(amps[0].inputs, 0)
+		signal := 0
+		for halted := false; !halted; {
+			for i, amp := range amps {
+				e, err := amp.runUntilEvent()
+				for e == Output && err == nil {
+					e, err = amp.runUntilEvent()
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+				next := amps[(i+1)%len(amps)]
+				next.inputs = append(next.inputs, amp.outputs...)
+				if i == len(amps)-1 && len(amp.outputs) > 0 {
+					signal = amp.outputs[len(amp.outputs)-1]
+				}
+				amp.outputs = amp.outputs[:0]
+				halted = e == Halted && i == len(amps)-1
+			}
+		}
+		if signal != 139629729 {
+			b.Fatalf("got signal %d, want 139629729", signal)
+		}
+	}
+}
+
+// TestBaseline checks that the baseline interpreter still runs the programs it's benchmarked on, and agrees with
+// the interpreter it's compared to.
+func TestBaseline(t *testing.T) {
+	want, err := NewWithNounVerb(nounVerbProgram, 81, 87, nil, nil).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := newBaseline(nounVerbProgram, 81, 87, true).run()
+	if err != nil || got != want {
+		t.Errorf("got %d and error %v, want %d", got, err, want)
+	}
+	if _, err := newBaseline(Program{42}, 0, 0, false).run(); !errors.Is(err, ErrUnknownOpcode) {
+		t.Errorf("got error %v, want %v", err, ErrUnknownOpcode)
+	}
+}
@@ -0,0 +1,446 @@
+package interpreter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrQuit is what a debugged machine stops with when the debugger is told to quit.
+var ErrQuit = errors.New("quit from the debugger")
+
+// Debugger is an interactive debugger for intcode machines. It stops the first machine attached to it before its
+// first instruction, and any machine at a breakpoint or after a write to a watched address, and then reads commands
+// a line at a time until one of them sets the machine going again. Type help at its prompt for the commands.
+//
+// It can be attached to machines that run at once. While one is stopped at the prompt the others run on,
+// and any of them that stops too waits for its turn at the prompt.
+type Debugger struct {
+	in  *bufio.Scanner
+	out io.Writer
+
+	// prompt is held by the machine that's taking commands, so that only one machine at a time reads them
+	prompt sync.Mutex
+	// mu guards the rest, but isn't held while waiting for a command
+	mu  sync.Mutex
+	ids map[*Interpreter]int
+	// current is the machine that stopped last, which stepping applies to
+	current *Interpreter
+	// steps is how many more instructions current executes before stopping again; 0 runs on until a breakpoint
+	steps   int
+	started bool
+
+	breaks    []breakpoint
+	lastBreak int
+	watches   map[uint]bool
+	// watched holds why a machine should stop before its next instruction, after it wrote to a watched address
+	watched map[*Interpreter]string
+	outputs []logged
+
+	// detached is set when the commands run out, after which machines run without stopping
+	detached bool
+	quit     bool
+}
+
+// breakpoint stops a machine at an address, or else at any instruction with an opcode.
+type breakpoint struct {
+	id   int
+	addr int // -1 for a breakpoint on an opcode
+	code op
+}
+
+func (b breakpoint) hit(i *Interpreter) bool {
+	if b.addr >= 0 {
+		return i.ipt == uint(b.addr)
+	}
+	return decode(i.get(i.ipt)).code == b.code
+}
+
+func (b breakpoint) String() string {
+	if b.addr >= 0 {
+		return fmt.Sprintf("breakpoint %d at %d", b.id, b.addr)
+	}
+	return fmt.Sprintf("breakpoint %d on %s", b.id, mnemonics[b.code])
+}
+
+// logged is a value output by a machine.
+type logged struct {
+	machine int
+	value   int
+}
+
+// NewDebugger makes a debugger that reads commands from in and writes to out.
+func NewDebugger(in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		in:      bufio.NewScanner(in),
+		out:     out,
+		ids:     make(map[*Interpreter]int),
+		watches: make(map[uint]bool),
+		watched: make(map[*Interpreter]string),
+	}
+}
+
+// Attach makes the debugger watch a machine. Machines are numbered from 1 in the order they're attached.
+// Set OnNew to it to debug every machine that a solver makes.
+func (d *Debugger) Attach(i *Interpreter) {
+	d.mu.Lock()
+	d.ids[i] = len(d.ids) + 1
+	d.mu.Unlock()
+	i.Observe(d)
+}
+
+// Machines is the number of machines that have been attached.
+func (d *Debugger) Machines() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.ids)
+}
+
+// Before stops the machine for commands if it's at a breakpoint, has written to a watched address,
+// or has taken as many steps as it was told to.
+func (d *Debugger) Before(i *Interpreter) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.quit {
+		return ErrQuit
+	}
+	if d.detached {
+		return nil
+	}
+
+	var reasons []string
+	if !d.started {
+		d.started = true
+		reasons = append(reasons, "started")
+	}
+	if i == d.current && d.steps > 0 {
+		if d.steps--; d.steps == 0 {
+			reasons = append(reasons, "stepped")
+		}
+	}
+	if r, ok := d.watched[i]; ok {
+		delete(d.watched, i)
+		reasons = append(reasons, r)
+	}
+	for _, b := range d.breaks {
+		if b.hit(i) {
+			reasons = append(reasons, b.String())
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	// wait for any other stopped machine to be sent on its way
+	d.mu.Unlock()
+	d.prompt.Lock()
+	defer d.prompt.Unlock()
+	d.mu.Lock()
+	if d.quit {
+		return ErrQuit
+	}
+	if d.detached {
+		return nil
+	}
+	d.current, d.steps = i, 0
+	fmt.Fprintf(d.out, "Machine %d stopped (%s) at\n", d.ids[i], strings.Join(reasons, ", "))
+	fmt.Fprintf(d.out, "%8d: %s\n", i.ipt, i.instrText(i.ipt))
+	return d.repl(i)
+}
+
+// Wrote notes a write to a watched address, to stop the machine before its next instruction.
+func (d *Debugger) Wrote(i *Interpreter, addr uint, old int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.watches[addr] && !d.detached {
+		d.watched[i] = fmt.Sprintf("watchpoint at %d: %d -> %d", addr, old, i.get(addr))
+	}
+}
+
+// Input does nothing; the debugger doesn't keep track of input.
+func (d *Debugger) Input(i *Interpreter, n int) {}
+
+// Output adds a value to the output log.
+func (d *Debugger) Output(i *Interpreter, n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.outputs = append(d.outputs, logged{d.ids[i], n})
+}
+
+// repl reads and runs commands for a stopped machine until one of them sets it going again.
+// It's called with d.prompt and d.mu held, and lets go of d.mu while it waits for each command.
+func (d *Debugger) repl(i *Interpreter) error {
+	for {
+		fmt.Fprint(d.out, "(intcode) ")
+		d.mu.Unlock()
+		ok := d.in.Scan()
+		d.mu.Lock()
+		if !ok {
+			fmt.Fprintln(d.out, "\nNo more commands; running without stopping.")
+			d.detached = true
+			return nil
+		}
+		fields := strings.Fields(d.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "help" || fields[0] == "h" {
+			d.help()
+			continue
+		}
+		cmd, ok := findDebugCommand(fields[0])
+		if !ok {
+			fmt.Fprintf(d.out, "Unknown command %q; type help for the commands.\n", fields[0])
+			continue
+		}
+		resume, err := cmd.run(d, i, fields[1:])
+		switch {
+		case err == ErrQuit:
+			d.quit = true
+			return err
+		case err != nil:
+			fmt.Fprintf(d.out, "%s: %v\n", cmd.name, err)
+		case resume:
+			return nil
+		}
+	}
+}
+
+func (d *Debugger) help() {
+	for _, cmd := range debugCommands {
+		usage := cmd.name
+		if cmd.short != "" {
+			usage += ", " + cmd.short
+		}
+		if cmd.args != "" {
+			usage += " " + cmd.args
+		}
+		fmt.Fprintf(d.out, "  %-26s %s\n", usage, cmd.help)
+	}
+	fmt.Fprintf(d.out, "  %-26s %s\n", "help, h", "show this list")
+}
+
+// debugCommand is a command of the debugger. Its run function says whether the machine should go on running.
+type debugCommand struct {
+	name  string
+	short string
+	args  string
+	help  string
+	run   func(d *Debugger, i *Interpreter, args []string) (resume bool, err error)
+}
+
+func findDebugCommand(name string) (debugCommand, bool) {
+	for _, cmd := range debugCommands {
+		if name == cmd.name || (cmd.short != "" && name == cmd.short) {
+			return cmd, true
+		}
+	}
+	return debugCommand{}, false
+}
+
+var debugCommands = []debugCommand{
+	{"step", "s", "[n]", "run n instructions of this machine (default 1)", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return false, fmt.Errorf("the number of steps must be a positive integer")
+			}
+		}
+		d.steps = n
+		return true, nil
+	}},
+	{"continue", "c", "", "run until a breakpoint or watchpoint", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		return true, nil
+	}},
+	{"break", "b", "<addr | mnemonic>", "stop at an address, or at any instruction like JZ", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		if len(args) != 1 {
+			return false, fmt.Errorf("give an address or a mnemonic")
+		}
+		d.lastBreak++
+		b := breakpoint{id: d.lastBreak, addr: -1}
+		if code, err := lookupOpcode(strings.ToUpper(args[0])); err == nil {
+			b.code = code
+		} else if addr, err := parseAddr(args[0]); err == nil {
+			b.addr = int(addr)
+		} else {
+			d.lastBreak--
+			return false, fmt.Errorf("%q is neither an address nor a mnemonic", args[0])
+		}
+		d.breaks = append(d.breaks, b)
+		fmt.Fprintln(d.out, "Set", b)
+		return false, nil
+	}},
+	{"delete", "d", "<n>", "delete breakpoint n", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		if len(args) == 1 {
+			if id, err := strconv.Atoi(args[0]); err == nil {
+				for j, b := range d.breaks {
+					if b.id == id {
+						d.breaks = append(d.breaks[:j], d.breaks[j+1:]...)
+						fmt.Fprintln(d.out, "Deleted", b)
+						return false, nil
+					}
+				}
+			}
+		}
+		return false, fmt.Errorf("give the number of a breakpoint; list them with breaks")
+	}},
+	{"watch", "w", "<addr>", "stop after any write to an address", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		if len(args) != 1 {
+			return false, fmt.Errorf("give an address")
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		d.watches[addr] = true
+		return false, nil
+	}},
+	{"unwatch", "", "<addr>", "stop watching an address", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		if len(args) != 1 {
+			return false, fmt.Errorf("give an address")
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		delete(d.watches, addr)
+		return false, nil
+	}},
+	{"breaks", "", "", "list breakpoints and watchpoints", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		for _, b := range d.breaks {
+			fmt.Fprintln(d.out, " ", b)
+		}
+		for _, addr := range sortedAddrs(d.watches) {
+			fmt.Fprintf(d.out, "  watchpoint at %d\n", addr)
+		}
+		return false, nil
+	}},
+	{"regs", "r", "", "show this machine's registers and queued input", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		fmt.Fprintf(d.out, "machine %d\nipt %d: %s\nrpt %d\ninput %v\n", d.ids[i], i.ipt, i.instrText(i.ipt), i.rpt, i.inputs)
+		return false, nil
+	}},
+	{"mem", "m", "<addr> [n]", "show n values of memory from an address (default 8)", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		addr, n, err := parseRange(args, 8)
+		if err != nil {
+			return false, err
+		}
+		const perLine = 8
+		for j := uint(0); j < n; j += perLine {
+			values := make([]string, 0, perLine)
+			for k := j; k < n && k < j+perLine; k++ {
+				values = append(values, strconv.Itoa(i.get(addr+k)))
+			}
+			fmt.Fprintf(d.out, "%8d: %s\n", addr+j, strings.Join(values, " "))
+		}
+		return false, nil
+	}},
+	{"dis", "x", "[addr] [n]", "disassemble n instructions from an address (default ipt, 5)", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		if len(args) == 0 {
+			args = []string{strconv.FormatUint(uint64(i.ipt), 10)}
+		}
+		addr, n, err := parseRange(args, 5)
+		if err != nil {
+			return false, err
+		}
+		for j := uint(0); j < n; j++ {
+			marker := " "
+			if addr == i.ipt {
+				marker = ">"
+			}
+			fmt.Fprintf(d.out, "%s%7d: %s\n", marker, addr, i.instrText(addr))
+			if code := decode(i.get(addr)).code; mnemonics[code] != "" {
+				addr += paramCount(code)
+			}
+			addr++
+		}
+		return false, nil
+	}},
+	{"set", "", "<addr> <value>", "write a value to memory", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		if len(args) != 2 {
+			return false, fmt.Errorf("give an address and a value")
+		}
+		addr, err := parseAddr(args[0])
+		if err != nil {
+			return false, err
+		}
+		v, err := strconv.Atoi(args[1])
+		if err != nil {
+			return false, fmt.Errorf("the value must be an integer")
+		}
+		i.set(addr, v)
+		return false, nil
+	}},
+	{"input", "i", "<values...>", "queue values for this machine to read before any others", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		values := make([]int, len(args))
+		for j, arg := range args {
+			var err error
+			if values[j], err = strconv.Atoi(arg); err != nil {
+				return false, fmt.Errorf("input values must be integers")
+			}
+		}
+		i.inputs = append(values, i.inputs...)
+		return false, nil
+	}},
+	{"output", "o", "[n]", "show the last n values output by any machine (default 20)", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		n := 20
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 0 {
+				return false, fmt.Errorf("the count must be a non-negative integer")
+			}
+		}
+		start := len(d.outputs) - n
+		if start < 0 {
+			start = 0
+		}
+		for _, o := range d.outputs[start:] {
+			fmt.Fprintf(d.out, "  machine %d: %d\n", o.machine, o.value)
+		}
+		return false, nil
+	}},
+	{"quit", "q", "", "stop every machine, failing the run", func(d *Debugger, i *Interpreter, args []string) (bool, error) {
+		return false, ErrQuit
+	}},
+}
+
+func parseAddr(s string) (uint, error) {
+	addr, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("%q isn't an address", s)
+	}
+	return uint(addr), nil
+}
+
+// parseRange parses an address and an optional count, which is n if it's left out.
+func parseRange(args []string, n uint) (uint, uint, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return 0, 0, fmt.Errorf("give an address and optionally a count")
+	}
+	addr, err := parseAddr(args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(args) == 2 {
+		count, err := strconv.ParseUint(args[1], 10, 0)
+		if err != nil {
+			return 0, 0, fmt.Errorf("the count must be a non-negative integer")
+		}
+		n = uint(count)
+	}
+	return addr, n, nil
+}
+
+func sortedAddrs(set map[uint]bool) []uint {
+	addrs := make([]uint, 0, len(set))
+	for addr := range set {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(a, b int) bool { return addrs[a] < addrs[b] })
+	return addrs
+}
@@ -0,0 +1,153 @@
+package interpreter
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// debugProgram adds 2 and 3 into address 20, reads into address 21, outputs address 21 and halts.
+var debugProgram = Program{1101, 2, 3, 20, 3, 21, 4, 21, 99}
+
+// debug runs debugProgram under a debugger that reads the script as its commands, and returns the machine,
+// everything the debugger wrote and the error the machine stopped with.
+func debug(t *testing.T, script string) (*Interpreter, string, error) {
+	t.Helper()
+	var out strings.Builder
+	d := NewDebugger(strings.NewReader(script), &out)
+	i := New(debugProgram, nil, nil)
+	d.Attach(i)
+	_, err := i.Run()
+	return i, out.String(), err
+}
+
+func TestDebugger(t *testing.T) {
+	i, out, err := debug(t, strings.Join([]string{
+		// the machine would stop for input before reaching the watchpoint without any
+		"input 1",
+		"watch 20",
+		"continue",
+		"break 6",
+		"mem 20 2",
+		"input 7",
+		"step",
+		"set 21 9",
+		"continue",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("%v; the debugger wrote:\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Machine 1 stopped (started) at\n       0: ADD",
+		"Machine 1 stopped (watchpoint at 20: 0 -> 5) at\n       4: IN",
+		"      20: 5 0\n",
+		"Machine 1 stopped (stepped, breakpoint 1 at 6) at\n       6: OUT",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the debugger didn't write %q; it wrote:\n%s", want, out)
+		}
+	}
+	if got := i.Peek(21); got != 9 {
+		t.Errorf("address 21 is %d, want the 9 that was set", got)
+	}
+	if got, ok := i.PopOutput(); !ok || got != 9 {
+		t.Errorf("output %d, %v, want 9", got, ok)
+	}
+	if want := []int{1}; len(i.inputs) != 1 || i.inputs[0] != 1 {
+		t.Errorf("input left is %v, want %v: input from the debugger should come first", i.inputs, want)
+	}
+}
+
+func TestDebuggerBreakOnOpcode(t *testing.T) {
+	_, out, _ := debug(t, "break OUT\ninput 1\ncontinue\nregs\ncontinue\n")
+	if want := "Machine 1 stopped (breakpoint 1 on OUT) at\n       6: OUT"; !strings.Contains(out, want) {
+		t.Errorf("the debugger didn't write %q; it wrote:\n%s", want, out)
+	}
+	if want := "ipt 6: OUT [21]\nrpt 0\ninput []\n"; !strings.Contains(out, want) {
+		t.Errorf("the debugger didn't write %q; it wrote:\n%s", want, out)
+	}
+}
+
+func TestDebuggerBadCommands(t *testing.T) {
+	_, out, _ := debug(t, "jump 4\nstep 0\nbreak nowhere\nset 1\ninput x\nmem\n")
+	for _, want := range []string{
+		`Unknown command "jump"`,
+		"step: the number of steps must be a positive integer",
+		`break: "nowhere" is neither an address nor a mnemonic`,
+		"set: give an address and a value",
+		"input: input values must be integers",
+		"mem: give an address and optionally a count",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the debugger didn't write %q; it wrote:\n%s", want, out)
+		}
+	}
+}
+
+func TestDebuggerQuit(t *testing.T) {
+	if _, out, err := debug(t, "quit\n"); !errors.Is(err, ErrQuit) {
+		t.Errorf("got error %v, want %v; the debugger wrote:\n%s", err, ErrQuit, out)
+	}
+}
+
+func TestDebuggerRunsOutOfCommands(t *testing.T) {
+	_, out, err := debug(t, "input 4\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "No more commands") {
+		t.Errorf("the debugger didn't say it ran out of commands; it wrote:\n%s", out)
+	}
+}
+
+// promptWriter signals on prompted each time the debugger prompts for a command.
+type promptWriter struct {
+	prompted chan struct{}
+}
+
+func (w promptWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), "(intcode) ") {
+		w.prompted <- struct{}{}
+	}
+	return len(p), nil
+}
+
+// TestDebuggerOthersRunOn checks that a machine that's stopped at the prompt doesn't hold up the others.
+func TestDebuggerOthersRunOn(t *testing.T) {
+	in, commands := io.Pipe()
+	w := promptWriter{make(chan struct{}, 1)}
+	d := NewDebugger(in, w)
+	stopped, other := New(debugProgram, nil, nil), New(debugProgram, nil, nil)
+	d.Attach(stopped)
+	d.Attach(other)
+	stopped.PushInput(1)
+	other.PushInput(2)
+
+	done := make(chan error)
+	go func() {
+		_, err := stopped.Run()
+		done <- err
+	}()
+	<-w.prompted
+
+	ran := make(chan error)
+	go func() {
+		_, err := other.Run()
+		ran <- err
+	}()
+	select {
+	case err := <-ran:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("another machine didn't run while the first was stopped at the prompt")
+	}
+
+	commands.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,211 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mnemonics of the opcodes, as the disassembler writes them
+var mnemonics = map[op]string{
+	oAdd:           "ADD",
+	oMul:           "MUL",
+	oIn:            "IN",
+	oOut:           "OUT",
+	oJumpIfNonZero: "JNZ",
+	oJumpIfZero:    "JZ",
+	oLessThan:      "LT",
+	oEquals:        "EQ",
+	oMoveRpt:       "ARB",
+	oHalt:          "HLT",
+}
+
+// writeParam is the parameter of each instruction that's written to, if it has one.
+var writeParam = map[op]uint{
+	oAdd:      2,
+	oMul:      2,
+	oIn:       0,
+	oLessThan: 2,
+	oEquals:   2,
+}
+
+// paramCount is the number of parameters an instruction takes. Unlike arity, it knows about halt.
+func paramCount(code op) uint {
+	if code == oHalt {
+		return 0
+	}
+	return arity[code]
+}
+
+// encode makes the raw value of an instruction from its opcode and the modes of its parameters.
+func encode(code op, modes []mode) int {
+	raw, scale := int(code), 100
+	for _, m := range modes {
+		raw += int(m) * scale
+		scale *= 10
+	}
+	return raw
+}
+
+// decodeExact decodes the instruction at addr if it's one that an assembler would write exactly that way:
+// a known opcode whose parameters fit in the program, with known modes, no immediate mode for a parameter
+// that's written to, and no digits for parameters it doesn't have.
+func decodeExact(p Program, addr int) (instr, bool) {
+	in := decode(p[addr])
+	if _, ok := mnemonics[in.code]; !ok {
+		return in, false
+	}
+	n := paramCount(in.code)
+	if addr+int(n) >= len(p) || encode(in.code, in.modes[:n]) != in.raw {
+		return in, false
+	}
+	for j := uint(0); j < n; j++ {
+		if in.modes[j] > mRelative {
+			return in, false
+		}
+	}
+	if w, ok := writeParam[in.code]; ok && in.modes[w] == mImmediate {
+		return in, false
+	}
+	return in, true
+}
+
+// operandText writes a parameter the way the assembler reads it.
+func operandText(m mode, v int) string {
+	switch {
+	case m == mPosition:
+		return fmt.Sprintf("[%d]", v)
+	case m == mImmediate:
+		return fmt.Sprintf("#%d", v)
+	case m != mRelative:
+		return fmt.Sprintf("?%d:%d", m, v)
+	case v < 0:
+		return fmt.Sprintf("rb%d", v)
+	}
+	return fmt.Sprintf("rb+%d", v)
+}
+
+// instrText writes the instruction at addr of a running machine's memory, or the value there if it isn't one.
+func (i *Interpreter) instrText(addr uint) string {
+	in := decode(i.get(addr))
+	name, ok := mnemonics[in.code]
+	if !ok {
+		return fmt.Sprintf("%s %d", dataDirective, in.raw)
+	}
+	n := paramCount(in.code)
+	operands := make([]string, n)
+	for j := range operands {
+		operands[j] = operandText(in.modes[j], i.get(addr+1+uint(j)))
+	}
+	return strings.TrimSpace(fmt.Sprintf("%-3s %s", name, strings.Join(operands, ", ")))
+}
+
+// Disassemble turns a program into annotated assembly, with the address of each line in a comment.
+// Assemble turns it back into the same program.
+//
+// Code is found by following the program's jumps from address 0, and also from return addresses that it pushes
+// with ADD or MUL onto the stack that rb points into. Targets of jumps are labeled L<address>. Everything that isn't
+// reached that way is taken to be data, and written with DATA directives.
+//
+// Parameters are written [x] in position mode, #x in immediate mode and rb+x in relative mode.
+func Disassemble(p Program) string {
+	starts := make(map[int]instr)
+	covered := make([]bool, len(p))
+	labels := make(map[int]bool)
+	// immediate parameters that refer to code, by address of the instruction and parameter
+	refs := make(map[[2]int]bool)
+
+	work := []int{0}
+	for len(work) > 0 {
+		addr := work[len(work)-1]
+		work = work[:len(work)-1]
+	linear:
+		for addr < len(p) && !covered[addr] {
+			in, ok := decodeExact(p, addr)
+			if !ok {
+				break
+			}
+			n := int(paramCount(in.code))
+			for j := addr; j <= addr+n; j++ {
+				if covered[j] {
+					break linear
+				}
+			}
+			starts[addr] = in
+			for j := addr; j <= addr+n; j++ {
+				covered[j] = true
+			}
+
+			param := func(j int) int { return p[addr+1+j] }
+			switch in.code {
+			case oHalt:
+				break linear
+			case oJumpIfNonZero, oJumpIfZero:
+				if in.modes[1] == mImmediate && param(1) >= 0 && param(1) < len(p) {
+					labels[param(1)] = true
+					refs[[2]int{addr, 1}] = true
+					work = append(work, param(1))
+				}
+				// a jump with an immediate condition always or never happens
+				if in.modes[0] == mImmediate && (param(0) != 0) == (in.code == oJumpIfNonZero) {
+					break linear
+				}
+			case oAdd, oMul:
+				// pushing a return address looks like ADD #ret, #0, rb+x or MUL #ret, #1, rb+x
+				identity := 0
+				if in.code == oMul {
+					identity = 1
+				}
+				if in.modes[0] == mImmediate && in.modes[1] == mImmediate && in.modes[2] == mRelative && param(1) == identity &&
+					param(0) >= 0 && param(0) < len(p) {
+					labels[param(0)] = true
+					refs[[2]int{addr, 0}] = true
+					work = append(work, param(0))
+				}
+			}
+			addr += n + 1
+		}
+	}
+
+	// only code gets labels; references to anywhere else are left as numbers
+	for addr := range labels {
+		if _, ok := starts[addr]; !ok {
+			delete(labels, addr)
+		}
+	}
+
+	var b strings.Builder
+	line := func(text string, addr int) {
+		fmt.Fprintf(&b, "    %-36s ; %d\n", text, addr)
+	}
+	for addr := 0; addr < len(p); {
+		if labels[addr] {
+			fmt.Fprintf(&b, "L%d:\n", addr)
+		}
+		if in, ok := starts[addr]; ok {
+			n := int(paramCount(in.code))
+			operands := make([]string, n)
+			for j := range operands {
+				v := p[addr+1+j]
+				if refs[[2]int{addr, j}] && labels[v] {
+					operands[j] = fmt.Sprintf("#L%d", v)
+				} else {
+					operands[j] = operandText(in.modes[j], v)
+				}
+			}
+			line(strings.TrimSpace(fmt.Sprintf("%-3s %s", mnemonics[in.code], strings.Join(operands, ", "))), addr)
+			addr += n + 1
+			continue
+		}
+
+		// data runs until the next code or label, a few values to a line
+		const perLine = 8
+		var values []string
+		start := addr
+		for addr < len(p) && len(values) < perLine && !covered[addr] && (addr == start || !labels[addr]) {
+			values = append(values, fmt.Sprint(p[addr]))
+			addr++
+		}
+		line("DATA "+strings.Join(values, ", "), start)
+	}
+	return b.String()
+}
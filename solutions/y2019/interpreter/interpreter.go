@@ -2,48 +2,78 @@
 package interpreter
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
-
-	"github.com/jzimbel/adventofcode-go/solutions/common"
 )
 
-// Program code / memory of a running Program.
-type Program map[uint]int
+// Program is the code and initial memory of an intcode program.
+type Program []int
 
 // Interpreter executes a set of instructions.
 // Instantiate with one of the `New*` functions.
 type Interpreter struct {
-	mem    Program
-	ipt    uint
-	rpt    uint
+	mem memory
+	ipt uint
+	// rpt is signed so that a program can move it below 0 without wrapping around; using it there is an error
+	rpt    int
 	input  func() int
 	output func(int)
+	// inputs and outputs are queued here for machines without input and output functions
+	inputs  []int
+	outputs []int
+	// observer, if set, watches everything the machine does
+	observer Observer
 }
 
-// parameter mode
-type mode uint
-
-// opcode
-type op uint
-
-// pairs a function that executes an instruction with its arity
-// and indices of args (if any) that are used for writing values to memory
-type procedure struct {
-	f         func(i *Interpreter, args ...int) (skipIncrement bool)
-	arity     uint
-	writeArgs set
+// Observer watches a machine run, such as a debugger. Its methods are called as things happen on the machine's
+// own goroutine, so an observer of machines that run at once has to do its own locking.
+type Observer interface {
+	// Before is called before each instruction is executed, with ipt at the instruction. An error stops the machine
+	// with that error, before it executes the instruction.
+	Before(i *Interpreter) error
+	// Wrote is called after a write to memory, with the value that was there before it.
+	Wrote(i *Interpreter, addr uint, old int)
+	// Input is called with each value that an input instruction reads.
+	Input(i *Interpreter, n int)
+	// Output is called with each value that an output instruction writes.
+	Output(i *Interpreter, n int)
 }
 
-// stores the information found in an opcode
-type opDesc struct {
-	proc *procedure
-	m    [3]mode
+// OnNew, if it's set, is called with each interpreter that New makes, before it runs. It lets a debugger or
+// a tracer watch the machines that a solver makes, without the solver knowing about it.
+var OnNew func(i *Interpreter)
+
+// Event is what stopped RunUntilEvent.
+type Event int
+
+const (
+	// Halted means the program reached a halt instruction. Running it again halts again straight away.
+	Halted Event = iota
+	// NeedInput means the program is at an input instruction with no input queued. Queue some with PushInput.
+	NeedInput
+	// Output means the program produced a value. Take it with PopOutput.
+	Output
+)
+
+func (e Event) String() string {
+	switch e {
+	case Halted:
+		return "halted"
+	case NeedInput:
+		return "need input"
+	case Output:
+		return "output"
+	}
+	return fmt.Sprintf("Event(%d)", int(e))
 }
 
-// Set type allows for efficient existence checking
-type set map[uint]struct{}
+// parameter mode
+type mode uint8
+
+// opcode
+type op uint8
 
 const (
 	mPosition mode = iota
@@ -65,26 +95,123 @@ const (
 	oHalt op = 99
 )
 
-var procedures map[op]*procedure
+// number of parameters each instruction takes
+var arity = [...]uint{
+	oAdd:           3,
+	oMul:           3,
+	oIn:            1,
+	oOut:           1,
+	oJumpIfNonZero: 2,
+	oJumpIfZero:    2,
+	oLessThan:      3,
+	oEquals:        3,
+	oMoveRpt:       1,
+}
+
+// Errors that Run reports, wrapped in an *Error that says where they happened.
+var (
+	ErrUnknownOpcode   = errors.New("unknown opcode")
+	ErrBadMode         = errors.New("unknown parameter mode")
+	ErrNegativeAddress = errors.New("negative address")
+	ErrWriteImmediate  = errors.New("immediate mode used for a parameter that's written to")
+	ErrNoInput         = errors.New("input instruction with no input function")
+)
+
+// excerptLen is how much memory an Error shows, enough for the longest instruction.
+const excerptLen = 4
+
+// Error is an instruction that the interpreter couldn't execute.
+// Use errors.Is to tell which of the Err values it is.
+type Error struct {
+	Err error
+	// Ipt is the address of the instruction.
+	Ipt uint
+	// Opcode is the raw value at Ipt, parameter modes included.
+	Opcode int
+	// Mem is the memory starting at Ipt.
+	Mem []int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("intcode: %v in instruction %d at address %d; memory there is %v", e.Err, e.Opcode, e.Ipt, e.Mem)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// fail wraps err in an *Error about the current instruction.
+func (i *Interpreter) fail(err error) *Error {
+	e := &Error{Err: err, Ipt: i.ipt, Opcode: i.get(i.ipt), Mem: make([]int, excerptLen)}
+	for j := range e.Mem {
+		e.Mem[j] = i.get(i.ipt + uint(j))
+	}
+	return e
+}
 
-// New interpreter.
+// New interpreter. The input and output functions may be nil: input is then taken from values queued
+// with PushInput, and output is queued for PopOutput.
 func New(initMem Program, input func() int, output func(int)) (i *Interpreter) {
-	mem := make(Program, len(initMem))
-	for i, n := range initMem {
-		mem[i] = n
+	return announce(&Interpreter{mem: newMemory(initMem), input: input, output: output})
+}
+
+// announce passes a new interpreter to OnNew, if it's set.
+func announce(i *Interpreter) *Interpreter {
+	if OnNew != nil {
+		OnNew(i)
 	}
-	return &Interpreter{mem: mem, input: input, output: output}
+	return i
+}
+
+// Clone makes a copy of the machine as it is now, with the same input and output functions and copies of its
+// queued input and output, which then runs by itself. The two share memory, and a write by either copies only
+// the page of memory it's on, so cloning is cheap even for a machine with a lot of memory, as when exploring
+// the branches of a search from where it's got to.
+// The clone isn't watched by the machine's observer, but is passed to OnNew like a new machine.
+func (i *Interpreter) Clone() *Interpreter {
+	return announce(&Interpreter{
+		mem:     i.mem.clone(),
+		ipt:     i.ipt,
+		rpt:     i.rpt,
+		input:   i.input,
+		output:  i.output,
+		inputs:  append([]int(nil), i.inputs...),
+		outputs: append([]int(nil), i.outputs...),
+	})
+}
+
+// Observe makes o watch the machine, in place of any observer it had. A nil o stops it being watched.
+func (i *Interpreter) Observe(o Observer) {
+	i.observer = o
+}
+
+// Ipt is the address of the next instruction to execute.
+func (i *Interpreter) Ipt() uint {
+	return i.ipt
+}
+
+// Rpt is the relative base.
+func (i *Interpreter) Rpt() int {
+	return i.rpt
+}
+
+// Peek reads memory at addr, without the program seeing it.
+func (i *Interpreter) Peek(addr uint) int {
+	return i.get(addr)
+}
+
+// Poke writes val to memory at addr, as NewWithNounVerb does with the noun and verb. It isn't seen by the
+// machine's observer.
+func (i *Interpreter) Poke(addr uint, val int) {
+	i.set(addr, val)
 }
 
 // NewWithNounVerb produces an interpreter with memory at addresses 1 and 2 replaced by noun and verb.
 func NewWithNounVerb(initMem Program, noun int, verb int, input func() int, output func(int)) *Interpreter {
-	mem := make(Program, len(initMem))
-	for i, n := range initMem {
-		mem[i] = n
-	}
-	mem[1] = noun
-	mem[2] = verb
-	return &Interpreter{mem: mem, input: input, output: output}
+	i := New(initMem, input, output)
+	i.set(1, noun)
+	i.set(2, verb)
+	return i
 }
 
 // ParseMem parses the initial memory/instructions of an intcode program from a puzzle input.
@@ -92,214 +219,220 @@ func ParseMem(input string) (mem Program) {
 	numbers := strings.Split(input, ",")
 	mem = make(Program, len(numbers))
 	for i, n := range numbers {
-		intn, _ := strconv.Atoi(n)
-		mem[uint(i)] = intn
+		mem[i], _ = strconv.Atoi(n)
 	}
 	return
 }
 
-// Run runs the interpreter until it encounters a halt opcode.
+// Run runs the interpreter until it encounters a halt opcode, and returns the value at address 0.
+// An instruction that can't be executed stops it with an *Error.
 func (i *Interpreter) Run() (int, error) {
-	var o *opDesc
-	var skipIncrement bool
 	for {
-		o = getOpDesc(i.get(i.ipt))
-		if o.proc.f == nil {
-			// halt code encountered
-			break
-		}
-
-		args := make([]int, o.proc.arity)
-		var j uint
-		for j = 0; j < o.proc.arity; j++ {
-			args[j] = i.getParam(j+1, o.m[j], o.proc.writeArgs.has(j))
+		e, err := i.RunUntilEvent()
+		if err != nil {
+			return 0, err
 		}
-
-		skipIncrement = o.proc.f(i, args...)
-		if !skipIncrement {
-			i.ipt += o.proc.arity + 1
+		switch e {
+		case Halted:
+			return i.get(0), nil
+		case NeedInput:
+			return 0, i.fail(ErrNoInput)
 		}
+		// without an output function, outputs are left queued
 	}
-	return i.get(0), nil
-}
-
-func (i *Interpreter) get(addr uint) (val int) {
-	val, ok := i.mem[addr]
-	if !ok {
-		val = 0
-	}
-	return
-}
-
-func (i *Interpreter) set(addr uint, val int) {
-	i.mem[addr] = val
 }
 
-func (i *Interpreter) getParam(offset uint, m mode, isWriteArg bool) (v int) {
-	if isWriteArg {
-		switch m {
-		case mPosition:
-			v = i.codeAt(offset)
-		case mRelative:
-			v = int(i.rpt) + i.codeAt(offset)
-		default:
-			panic("immediate mode used for write arg")
+// RunUntilEvent runs the interpreter until the program halts, needs input that hasn't been queued, or produces
+// output with no output function to take it. Call it again to carry on, after queueing input for NeedInput.
+// This lets a caller drive machines one after another, without goroutines or callbacks.
+func (i *Interpreter) RunUntilEvent() (Event, error) {
+	for {
+		e, stop, err := i.step()
+		if err != nil {
+			return Halted, i.fail(err)
 		}
-	} else {
-		switch m {
-		case mPosition:
-			v = i.get(uint(i.codeAt(offset)))
-		case mImmediate:
-			v = i.codeAt(offset)
-		case mRelative:
-			v = i.get(i.rpt + uint(i.codeAt(offset)))
-		default:
-			panic(fmt.Sprintf("unknown parameter mode encountered: %d", m))
+		if stop {
+			return e, nil
 		}
 	}
-	return
 }
 
-func (i *Interpreter) codeAt(offset uint) int {
-	return i.get(i.ipt + offset)
+// PushInput queues values for the program's input instructions to read, in order.
+// They're read before the input function, if the interpreter has one, is asked for any more.
+func (i *Interpreter) PushInput(values ...int) {
+	i.inputs = append(i.inputs, values...)
 }
 
-func makeSet(values ...uint) (s set) {
-	s = make(set, len(values))
-	for _, value := range values {
-		s[value] = struct{}{}
+// PopOutput takes the oldest value that the program output, if there are any left.
+// Outputs are only queued if the interpreter has no output function.
+func (i *Interpreter) PopOutput() (n int, ok bool) {
+	if len(i.outputs) == 0 {
+		return 0, false
 	}
-	return
+	n, i.outputs = i.outputs[0], i.outputs[1:]
+	return n, true
 }
 
-func (s set) has(value uint) (ok bool) {
-	_, ok = s[value]
-	return
-}
-
-func getOpDesc(o int) *opDesc {
-	return &opDesc{
-		proc: procedures[op(common.GetDigits(o, 0, 2))],
-		m: [...]mode{
-			mode(common.GetDigit(o, 2)),
-			mode(common.GetDigit(o, 3)),
-			mode(common.GetDigit(o, 4)),
-		},
+// step executes the instruction at ipt. It stops, with the event that stopped it, at a halt instruction,
+// at an input instruction with no input to read, which isn't executed, or after output that was queued.
+func (i *Interpreter) step() (e Event, stop bool, err error) {
+	in := i.mem.fetch(i.ipt)
+	if in.code == oIn && i.input == nil && len(i.inputs) == 0 {
+		return NeedInput, true, nil
+	}
+	if i.observer != nil {
+		if err = i.observer.Before(i); err != nil {
+			return
+		}
+		// the observer can change memory, so the instruction may have changed
+		in = i.mem.fetch(i.ipt)
+		if in.code == oIn && i.input == nil && len(i.inputs) == 0 {
+			return NeedInput, true, nil
+		}
+	}
+	if in.code == oHalt {
+		return Halted, true, nil
+	}
+	if int(in.code) >= len(arity) || arity[in.code] == 0 {
+		return Halted, false, ErrUnknownOpcode
 	}
-}
-
-func add(i *Interpreter, args ...int) (skipIncrement bool) {
-	i.set(uint(args[2]), args[0]+args[1])
-	return
-}
 
-func mul(i *Interpreter, args ...int) (skipIncrement bool) {
-	i.set(uint(args[2]), args[0]*args[1])
+	var a, b int
+	switch in.code {
+	case oAdd, oMul, oLessThan, oEquals:
+		if a, err = i.read(&in, 0); err != nil {
+			return
+		}
+		if b, err = i.read(&in, 1); err != nil {
+			return
+		}
+		var v int
+		switch in.code {
+		case oAdd:
+			v = a + b
+		case oMul:
+			v = a * b
+		case oLessThan:
+			if a < b {
+				v = 1
+			}
+		case oEquals:
+			if a == b {
+				v = 1
+			}
+		}
+		if err = i.write(&in, 2, v); err != nil {
+			return
+		}
+	case oIn:
+		var n int
+		queued := len(i.inputs) > 0
+		if queued {
+			n = i.inputs[0]
+		} else {
+			n = i.input()
+		}
+		if i.observer != nil {
+			i.observer.Input(i, n)
+		}
+		if err = i.write(&in, 0, n); err != nil {
+			return
+		}
+		if queued {
+			i.inputs = i.inputs[1:]
+		}
+	case oOut:
+		if a, err = i.read(&in, 0); err != nil {
+			return
+		}
+		if i.observer != nil {
+			i.observer.Output(i, a)
+		}
+		if i.output != nil {
+			i.output(a)
+		} else {
+			i.outputs = append(i.outputs, a)
+			e, stop = Output, true
+		}
+	case oJumpIfNonZero, oJumpIfZero:
+		if a, err = i.read(&in, 0); err != nil {
+			return
+		}
+		if b, err = i.read(&in, 1); err != nil {
+			return
+		}
+		if (a != 0) == (in.code == oJumpIfNonZero) {
+			if b < 0 {
+				return Halted, false, ErrNegativeAddress
+			}
+			i.ipt = uint(b)
+			return
+		}
+	case oMoveRpt:
+		if a, err = i.read(&in, 0); err != nil {
+			return
+		}
+		i.rpt += a
+	}
+	i.ipt += arity[in.code] + 1
 	return
 }
 
-func input(i *Interpreter, args ...int) (skipIncrement bool) {
-	i.set(uint(args[0]), i.input())
-	return
+func (i *Interpreter) get(addr uint) int {
+	return i.mem.get(addr)
 }
 
-func output(i *Interpreter, args ...int) (skipIncrement bool) {
-	i.output(args[0])
-	return
+func (i *Interpreter) set(addr uint, val int) {
+	i.mem.set(addr, val)
 }
 
-func jumpIfNonZero(i *Interpreter, args ...int) (skipIncrement bool) {
-	if args[0] != 0 {
-		i.ipt = uint(args[1])
-		skipIncrement = true
+// addr resolves the address that parameter n of an instruction refers to.
+func (i *Interpreter) addr(in *instr, n uint) (uint, error) {
+	var addr int
+	switch in.modes[n] {
+	case mPosition:
+		addr = i.codeAt(n + 1)
+	case mRelative:
+		addr = i.rpt + i.codeAt(n+1)
+	case mImmediate:
+		return 0, ErrWriteImmediate
+	default:
+		return 0, ErrBadMode
 	}
-	return
-}
-
-func jumpIfZero(i *Interpreter, args ...int) (skipIncrement bool) {
-	if args[0] == 0 {
-		i.ipt = uint(args[1])
-		skipIncrement = true
+	if addr < 0 {
+		return 0, ErrNegativeAddress
 	}
-	return
+	return uint(addr), nil
 }
 
-func lessThan(i *Interpreter, args ...int) (skipIncrement bool) {
-	if args[0] < args[1] {
-		i.set(uint(args[2]), 1)
-	} else {
-		i.set(uint(args[2]), 0)
+// read resolves the value of parameter n of an instruction.
+func (i *Interpreter) read(in *instr, n uint) (int, error) {
+	if in.modes[n] == mImmediate {
+		return i.codeAt(n + 1), nil
 	}
-	return
-}
-
-func equals(i *Interpreter, args ...int) (skipIncrement bool) {
-	if args[0] == args[1] {
-		i.set(uint(args[2]), 1)
-	} else {
-		i.set(uint(args[2]), 0)
+	addr, err := i.addr(in, n)
+	if err != nil {
+		return 0, err
 	}
-	return
+	return i.get(addr), nil
 }
 
-func moveRpt(i *Interpreter, args ...int) (skipIncrement bool) {
-	// might underflow if arg is negative, but we're going
-	// to trust that none of the intcode programs will do that
-	i.rpt = uint(int(i.rpt) + args[0])
-	return
+// write stores a value at the address that parameter n of an instruction refers to.
+func (i *Interpreter) write(in *instr, n uint, val int) error {
+	addr, err := i.addr(in, n)
+	if err != nil {
+		return err
+	}
+	if i.observer != nil {
+		old := i.get(addr)
+		i.set(addr, val)
+		i.observer.Wrote(i, addr, old)
+		return nil
+	}
+	i.set(addr, val)
+	return nil
 }
 
-func init() {
-	procedures = map[op]*procedure{
-		oAdd: {
-			f:         add,
-			arity:     3,
-			writeArgs: makeSet(2),
-		},
-		oMul: {
-			f:         mul,
-			arity:     3,
-			writeArgs: makeSet(2),
-		},
-		oIn: {
-			f:         input,
-			arity:     1,
-			writeArgs: makeSet(0),
-		},
-		oOut: {
-			f:         output,
-			arity:     1,
-			writeArgs: makeSet(),
-		},
-		oJumpIfNonZero: {
-			f:         jumpIfNonZero,
-			arity:     2,
-			writeArgs: makeSet(),
-		},
-		oJumpIfZero: {
-			f:         jumpIfZero,
-			arity:     2,
-			writeArgs: makeSet(),
-		},
-		oLessThan: {
-			f:         lessThan,
-			arity:     3,
-			writeArgs: makeSet(2),
-		},
-		oEquals: {
-			f:         equals,
-			arity:     3,
-			writeArgs: makeSet(2),
-		},
-		oMoveRpt: {
-			f:         moveRpt,
-			arity:     1,
-			writeArgs: makeSet(),
-		},
-		oHalt: {
-			f:         nil,
-			arity:     0,
-			writeArgs: makeSet(),
-		},
-	}
+func (i *Interpreter) codeAt(offset uint) int {
+	return i.get(i.ipt + offset)
 }
@@ -0,0 +1,123 @@
+package interpreter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		program Program
+		want    error
+		ipt     uint
+		opcode  int
+	}{
+		{"unknown opcode", Program{1, 0, 0, 0, 42}, ErrUnknownOpcode, 4, 42},
+		{"negative opcode", Program{-1}, ErrUnknownOpcode, 0, -1},
+		{"bad mode", Program{301, 0, 0, 0, 99}, ErrBadMode, 0, 301},
+		{"negative position", Program{1, -1, 0, 0, 99}, ErrNegativeAddress, 0, 1},
+		{"negative relative address", Program{109, -5, 22201, 0, 0, 0, 99}, ErrNegativeAddress, 2, 22201},
+		{"negative jump", Program{1105, 1, -1}, ErrNegativeAddress, 0, 1105},
+		{"write to immediate", Program{11101, 1, 1, 5, 99}, ErrWriteImmediate, 0, 11101},
+		{"no input", Program{3, 0, 99}, ErrNoInput, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.program, nil, nil).Run()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("got error of type %T, want *Error", err)
+			}
+			if e.Ipt != tt.ipt || e.Opcode != tt.opcode {
+				t.Errorf("error is at address %d with opcode %d, want address %d with opcode %d", e.Ipt, e.Opcode, tt.ipt, tt.opcode)
+			}
+		})
+	}
+}
+
+// pokeObserver writes a value to memory before the first instruction it sees.
+type pokeObserver struct {
+	addr uint
+	val  int
+	done bool
+}
+
+func (o *pokeObserver) Before(i *Interpreter) error {
+	if !o.done {
+		i.Poke(o.addr, o.val)
+		o.done = true
+	}
+	return nil
+}
+
+func (o *pokeObserver) Wrote(*Interpreter, uint, int) {}
+func (o *pokeObserver) Input(*Interpreter, int)       {}
+func (o *pokeObserver) Output(*Interpreter, int)      {}
+
+// TestObserverMakesInput checks that a machine with no input stops for some when an observer turns the next
+// instruction into an input instruction, rather than reading from an input function it doesn't have.
+func TestObserverMakesInput(t *testing.T) {
+	i := New(Program{99, 0, 99}, nil, nil)
+	i.Observe(&pokeObserver{addr: 0, val: 3})
+	if e, err := i.RunUntilEvent(); e != NeedInput || err != nil {
+		t.Fatalf("got event %v and error %v, want %v", e, err, NeedInput)
+	}
+	i.PushInput(7)
+	if e, err := i.RunUntilEvent(); e != Halted || err != nil {
+		t.Fatalf("got event %v and error %v, want %v", e, err, Halted)
+	}
+	if got := i.Peek(0); got != 7 {
+		t.Errorf("read %d into address 0, want 7", got)
+	}
+}
+
+// nounVerbProgram is a program like a day 2 input, made by its generator. Noun 81 and verb 87 make it output 19690720.
+var nounVerbProgram = ParseMem("1,0,0,3,1,1,146,145,2,145,147,145,2,145,148,145,2,145,149,145,1,145,150,145,1,145,151,145,1,145,152,145,1,145,153,145,1,145,154,145,1,145,155,145,1,145,156,145,1,145,157,145,1,145,158,145,1,145,159,145,1,145,160,145,1,145,161,145,1,145,162,145,1,145,163,145,1,145,164,145,1,145,165,145,1,145,166,145,1,145,167,145,1,145,168,145,1,145,169,145,1,145,170,145,1,145,171,145,1,145,172,145,1,145,173,145,1,145,174,145,1,145,175,145,1,145,176,145,1,145,177,145,1,145,178,145,1,145,179,145,1,145,2,0,99,0,0,9,5,3,535891,1031766,173654,284200,269041,108417,749729,43834,72093,118492,960433,859147,163493,82314,2004701,37880,734494,171505,2186346,92080,763956,2471496,1550037,987933,253097,714212,1223351,717184,26602,292320")
+
+// BenchmarkNounVerbSearch runs every noun and verb through a day 2 program, as day 2 part 2 does at worst.
+func BenchmarkNounVerbSearch(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		for noun := 0; noun < 100; noun++ {
+			for verb := 0; verb < 100; verb++ {
+				if _, err := NewWithNounVerb(nounVerbProgram, noun, verb, nil, nil).Run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+// feedbackProgram is the second example of day 7 part 2, which outputs 139629729 with phase settings 9, 8, 7, 6, 5.
+var feedbackProgram = ParseMem("3,26,1001,26,-4,26,3,27,1002,27,2,27,1,27,26,27,4,27,1001,28,-1,28,1005,28,6,99,0,0,5")
+
+// BenchmarkFeedbackLoop runs day 7's amplifiers in a feedback loop on a network.
+func BenchmarkFeedbackLoop(b *testing.B) {
+	// the last amplifier's output goes to the thrusters as well as back to the first
+	route := func(from int, size int, out []int) ([]Packet, []int) {
+		packets, rest := Ring(from, size, out)
+		if from == size-1 {
+			packets = append(packets, Packet{From: from, To: -1, Values: out})
+		}
+		return packets, rest
+	}
+	for n := 0; n < b.N; n++ {
+		amps := make([]*Interpreter, 5)
+		for i := range amps {
+			amps[i] = New(feedbackProgram, nil, nil)
+			amps[i].PushInput(9 - i)
+		}
+		net := NewNetwork(amps, route)
+		net.Send(Packet{From: -1, To: 0, Values: []int{0}})
+		if err := net.Run(); err != nil {
+			b.Fatal(err)
+		}
+		out := net.Receive()
+		if signal := out[len(out)-1].Values[0]; signal != 139629729 {
+			b.Fatalf("got signal %d, want 139629729", signal)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package interpreter
+
+// maxGrowth is how far past the end of a machine's pages a write can be and still add pages to reach it.
+// Writes further out go to a map instead, so that a program that writes to a huge address
+// doesn't make the interpreter allocate everything up to it.
+const maxGrowth = 1 << 16
+
+// Memory is kept in pages of this many cells, which are what clones copy when they write to memory they share.
+const (
+	pageBits = 8
+	pageSize = 1 << pageBits
+	pageMask = pageSize - 1
+)
+
+// memory is a running program's memory. It starts out as a copy of the program, and grows to take the data
+// that programs keep just past their code.
+type memory struct {
+	// pages hold the cells below size, pageSize to a page; only the last one can be shorter
+	pages []page
+	size  uint
+	far   map[uint]int
+	// ownFar is set when far belongs to this memory alone; see page
+	ownFar bool
+}
+
+// page is a run of pageSize cells of memory.
+type page struct {
+	cells []int
+	// decoded caches the instruction decoded from each cell, for the cells that have been executed.
+	// It's made when an instruction on the page is first fetched.
+	decoded []instr
+	// ownCells and ownDecoded are set when cells and decoded belong to this memory alone. Otherwise they're
+	// shared with a clone, and are copied before anything in them is changed, so a write only copies its page.
+	ownCells, ownDecoded bool
+}
+
+func newMemory(p Program) memory {
+	m := memory{ownFar: true}
+	m.grow(uint(len(p)))
+	for j := range m.pages {
+		copy(m.pages[j].cells, p[j<<pageBits:])
+	}
+	return m
+}
+
+func (m *memory) get(addr uint) int {
+	if addr < m.size {
+		return m.pages[addr>>pageBits].cells[addr&pageMask]
+	}
+	return m.far[addr]
+}
+
+// cells returns a copy of the memory below the far addresses.
+func (m *memory) cells() []int {
+	cells := make([]int, 0, m.size)
+	for _, pg := range m.pages {
+		cells = append(cells, pg.cells...)
+	}
+	return cells
+}
+
+// clone makes a copy of the memory that shares its pages with it until one of them writes to them.
+func (m *memory) clone() memory {
+	for j := range m.pages {
+		m.pages[j].ownCells, m.pages[j].ownDecoded = false, false
+	}
+	m.ownFar = false
+	c := *m
+	c.pages = append([]page(nil), m.pages...)
+	return c
+}
+
+// ownCells copies the cells of page j if they're shared, so that they can be changed.
+func (m *memory) ownCells(j uint) {
+	if pg := &m.pages[j]; !pg.ownCells {
+		pg.cells = append([]int(nil), pg.cells...)
+		pg.ownCells = true
+	}
+}
+
+// ownFarMap copies the map of far addresses if it's shared, so that it can be changed.
+func (m *memory) ownFarMap() {
+	if m.ownFar {
+		return
+	}
+	far := make(map[uint]int, len(m.far))
+	for addr, val := range m.far {
+		far[addr] = val
+	}
+	m.far, m.ownFar = far, true
+}
+
+func (m *memory) set(addr uint, val int) {
+	if addr >= m.size {
+		if addr-m.size >= maxGrowth {
+			m.ownFarMap()
+			if m.far == nil {
+				m.far = make(map[uint]int)
+			}
+			m.far[addr] = val
+			return
+		}
+		m.grow(addr + 1)
+	}
+	j := addr >> pageBits
+	m.ownCells(j)
+	m.pages[j].cells[addr&pageMask] = val
+}
+
+// grow extends the pages to n cells, moving in any that were written to the map.
+func (m *memory) grow(n uint) {
+	for m.size < n {
+		j := m.size >> pageBits
+		if j == uint(len(m.pages)) {
+			m.pages = append(m.pages, page{ownCells: true, ownDecoded: true})
+		}
+		end := (j + 1) << pageBits
+		if end > n {
+			end = n
+		}
+		m.ownCells(j)
+		pg := &m.pages[j]
+		pg.cells = append(pg.cells, make([]int, end-m.size)...)
+		m.size = end
+	}
+	var moved []uint
+	for addr, val := range m.far {
+		if addr < n {
+			m.pages[addr>>pageBits].cells[addr&pageMask] = val
+			moved = append(moved, addr)
+		}
+	}
+	if len(moved) > 0 {
+		m.ownFarMap()
+		for _, addr := range moved {
+			delete(m.far, addr)
+		}
+	}
+}
+
+// instr is a decoded instruction.
+type instr struct {
+	// raw is the value it was decoded from. A cached instruction is only used while its cell still holds
+	// the same value, since programs can overwrite their own code.
+	raw   int
+	code  op
+	modes [3]mode
+}
+
+func decode(raw int) instr {
+	in := instr{raw: raw}
+	if raw < 0 {
+		// leave the opcode as 0, which is unknown
+		return in
+	}
+	in.code = op(raw % 100)
+	modes := raw / 100
+	for j := range in.modes {
+		in.modes[j] = mode(modes % 10)
+		modes /= 10
+	}
+	return in
+}
+
+// fetch decodes the instruction at addr, using the cached decoding if the cell hasn't changed since.
+func (m *memory) fetch(addr uint) instr {
+	if addr >= m.size {
+		return decode(m.far[addr])
+	}
+	pg, k := &m.pages[addr>>pageBits], addr&pageMask
+	raw := pg.cells[k]
+	if !pg.ownDecoded || k >= uint(len(pg.decoded)) {
+		// the page's cache is shared with a clone, or doesn't cover the cell yet
+		decoded := make([]instr, len(pg.cells))
+		copy(decoded, pg.decoded)
+		pg.decoded, pg.ownDecoded = decoded, true
+	}
+	c := &pg.decoded[k]
+	if c.code == 0 || c.raw != raw {
+		*c = decode(raw)
+	}
+	return *c
+}
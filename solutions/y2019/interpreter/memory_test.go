@@ -0,0 +1,90 @@
+package interpreter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemoryGrow(t *testing.T) {
+	m := newMemory(Program{1, 2, 3})
+	m.set(2*pageSize+10, 5)
+	if m.size != 2*pageSize+11 || len(m.pages) != 3 || len(m.pages[2].cells) != 11 {
+		t.Fatalf("size %d in %d pages, the last of %d cells; want %d in 3, the last of 11", m.size, len(m.pages), len(m.pages[len(m.pages)-1].cells), 2*pageSize+11)
+	}
+	if m.get(2) != 3 || m.get(pageSize+1) != 0 || m.get(2*pageSize+10) != 5 {
+		t.Errorf("got %d, %d and %d, want 3, 0 and 5", m.get(2), m.get(pageSize+1), m.get(2*pageSize+10))
+	}
+
+	far := m.size + maxGrowth
+	m.set(far, 7)
+	if m.far[far] != 7 || m.get(far) != 7 {
+		t.Fatalf("a write %d past the end didn't go to the map", maxGrowth)
+	}
+	// growing up to the far write leaves it in the map, and growing past it moves it into the pages
+	m.set(far-1, 8)
+	if m.size != far || len(m.far) != 1 {
+		t.Fatalf("size %d with %d far addresses, want %d with 1", m.size, len(m.far), far)
+	}
+	m.set(far+1, 9)
+	if m.size != far+2 || len(m.far) != 0 {
+		t.Fatalf("size %d with %d far addresses, want %d with none", m.size, len(m.far), far+2)
+	}
+	if m.get(far-1) != 8 || m.get(far) != 7 || m.get(far+1) != 9 {
+		t.Errorf("got %d, %d and %d, want 8, 7 and 9", m.get(far-1), m.get(far), m.get(far+1))
+	}
+	if got := m.cells(); uint(len(got)) != m.size || got[far] != 7 {
+		t.Errorf("cells has %d values, with %d at %d; want %d, with 7", len(got), got[far], far, m.size)
+	}
+}
+
+// TestClonePages checks that a write to memory shared with a clone copies only the page it's on.
+func TestClonePages(t *testing.T) {
+	p := make(Program, 3*pageSize)
+	for j := range p {
+		p[j] = j
+	}
+	orig := newMemory(p)
+	orig.fetch(0)
+	clone := orig.clone()
+
+	orig.set(pageSize+1, -1)
+	clone.set(2*pageSize, -2)
+	clone.fetch(pageSize)
+	for _, m := range []*memory{&orig, &clone} {
+		m.set(5*pageSize, 1)
+	}
+
+	same := func(j int) bool { return &orig.pages[j].cells[0] == &clone.pages[j].cells[0] }
+	if !same(0) {
+		t.Error("page 0 was copied, but neither wrote to it")
+	}
+	if same(1) || same(2) {
+		t.Error("pages 1 and 2 weren't copied before they were written to")
+	}
+	if &orig.pages[0].decoded[0] != &clone.pages[0].decoded[0] {
+		t.Error("the decoded instructions of page 0 were copied, but neither fetched from it again")
+	}
+	if orig.get(pageSize+1) != -1 || clone.get(pageSize+1) != pageSize+1 {
+		t.Errorf("address %d is %d in the original and %d in the clone", pageSize+1, orig.get(pageSize+1), clone.get(pageSize+1))
+	}
+	if orig.get(2*pageSize) != 2*pageSize || clone.get(2*pageSize) != -2 {
+		t.Errorf("address %d is %d in the original and %d in the clone", 2*pageSize, orig.get(2*pageSize), clone.get(2*pageSize))
+	}
+	if !reflect.DeepEqual(clone.cells()[:pageSize], []int(p[:pageSize])) {
+		t.Error("page 0 changed")
+	}
+}
+
+// BenchmarkCloneLarge forks a machine with a megabyte-scale memory and runs the fork, which writes once to it,
+// as a search that explores the branches from a machine's state does.
+func BenchmarkCloneLarge(b *testing.B) {
+	p := make(Program, 1<<20)
+	copy(p, Program{1101, 1, 1, 1 << 19, 99})
+	i := New(p, nil, nil)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := i.Clone().Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package interpreter
+
+import "fmt"
+
+// Packet is a message that one machine in a network sends to another.
+type Packet struct {
+	From int
+	// To is the address of the receiving machine. Packets to addresses outside the network leave it,
+	// and can be picked up with Network.Receive.
+	To     int
+	Values []int
+}
+
+// Routing decides where the machines in a network send their output. It's given all of a machine's output that
+// hasn't been routed yet, and splits it into packets, returning whatever is left over for a later packet.
+type Routing func(from int, size int, out []int) (packets []Packet, rest []int)
+
+// Chain sends each value a machine outputs to the next machine. The last machine's output leaves the network.
+func Chain(from int, size int, out []int) ([]Packet, []int) {
+	return []Packet{{From: from, To: from + 1, Values: out}}, nil
+}
+
+// Ring sends each value a machine outputs to the next machine, and the last machine's to the first.
+func Ring(from int, size int, out []int) ([]Packet, []int) {
+	return []Packet{{From: from, To: (from + 1) % size, Values: out}}, nil
+}
+
+// Addressed routes output made of packets that each start with the receiving machine's address,
+// followed by length values.
+func Addressed(length int) Routing {
+	return func(from int, size int, out []int) (packets []Packet, rest []int) {
+		for len(out) >= length+1 {
+			packets = append(packets, Packet{From: from, To: out[0], Values: out[1 : length+1]})
+			out = out[length+1:]
+		}
+		return packets, out
+	}
+}
+
+// Network runs machines that talk to each other. Each machine's input queue is its address in the network.
+// The machines take turns, in order, each running until it needs input that hasn't arrived or halts,
+// so a network always runs the same way.
+type Network struct {
+	machines []*Interpreter
+	route    Routing
+	// EmptyInput, if it's set, is given to a machine that needs input when none has arrived,
+	// instead of leaving it waiting.
+	EmptyInput *int
+
+	// pending output of each machine that hasn't made a whole packet yet
+	pending [][]int
+	halted  []bool
+	// whether each machine has been waiting for input since it last sent or received anything
+	waiting []bool
+	outbox  []Packet
+}
+
+// NewNetwork makes a network of machines whose output is routed by r.
+// The machines mustn't have input or output functions.
+func NewNetwork(machines []*Interpreter, r Routing) *Network {
+	return &Network{
+		machines: machines,
+		route:    r,
+		pending:  make([][]int, len(machines)),
+		halted:   make([]bool, len(machines)),
+		waiting:  make([]bool, len(machines)),
+	}
+}
+
+// Machine returns the machine at an address.
+func (n *Network) Machine(addr int) *Interpreter {
+	return n.machines[addr]
+}
+
+// Send delivers a packet, from outside the network if its sender isn't one of the machines.
+func (n *Network) Send(p Packet) {
+	if p.To < 0 || p.To >= len(n.machines) {
+		n.outbox = append(n.outbox, p)
+		return
+	}
+	n.machines[p.To].PushInput(p.Values...)
+	n.waiting[p.To] = false
+}
+
+// Receive takes the packets that have left the network since it was last called.
+func (n *Network) Receive() []Packet {
+	out := n.outbox
+	n.outbox = nil
+	return out
+}
+
+// Halted reports whether every machine has halted.
+func (n *Network) Halted() bool {
+	for _, h := range n.halted {
+		if !h {
+			return false
+		}
+	}
+	return true
+}
+
+// Idle reports whether every machine that hasn't halted is waiting for input that hasn't arrived.
+// With EmptyInput set, that means each has been given it since it last sent or received anything.
+// A network that has halted is idle too.
+func (n *Network) Idle() bool {
+	for i, h := range n.halted {
+		if !h && !n.waiting[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Step gives each machine that hasn't halted one turn.
+func (n *Network) Step() error {
+	for i, m := range n.machines {
+		if n.halted[i] {
+			continue
+		}
+		if err := n.turn(i, m); err != nil {
+			return fmt.Errorf("machine %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Run gives the machines turns until every one of them has halted or the network is idle.
+func (n *Network) Run() error {
+	for {
+		if err := n.Step(); err != nil {
+			return err
+		}
+		if n.Idle() {
+			return nil
+		}
+	}
+}
+
+// turn runs a machine until it halts or needs input that hasn't arrived, routing what it outputs along the way.
+func (n *Network) turn(i int, m *Interpreter) error {
+	for {
+		e, err := m.RunUntilEvent()
+		if err != nil {
+			return err
+		}
+		switch e {
+		case Halted:
+			n.halted[i] = true
+			return nil
+		case NeedInput:
+			n.waiting[i] = true
+			if n.EmptyInput != nil {
+				// the machine reads it in its next turn, so that one that's polling for input still gives up this one
+				m.PushInput(*n.EmptyInput)
+			}
+			return nil
+		case Output:
+			out, _ := m.PopOutput()
+			n.waiting[i] = false
+			packets, rest := n.route(i, len(n.machines), append(n.pending[i], out))
+			n.pending[i] = rest
+			for _, p := range packets {
+				n.Send(p)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package interpreter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// incr reads a value, outputs it plus 1 and halts.
+var incr = Program{3, 0, 1001, 0, 1, 0, 4, 0, 99}
+
+// poll reads values until one isn't 0, then outputs it and halts.
+var poll = Program{3, 9, 1006, 9, 0, 4, 9, 99, 0, 0}
+
+func TestNetwork(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name       string
+		programs   []Program
+		route      Routing
+		emptyInput *int
+		send       []Packet
+		wantHalted bool
+		wantOut    []int
+	}{
+		{"chain", []Program{incr, incr}, Chain, nil, []Packet{{From: -1, To: 0, Values: []int{5}}}, true, []int{7}},
+		{"waiting for input", []Program{incr, incr}, Chain, nil, nil, false, nil},
+		{"addressed", []Program{{104, 1, 104, 42, 99}, {3, 100, 104, 5, 4, 100, 99}}, Addressed(1), nil, nil, true, []int{42}},
+		{"polling", []Program{poll}, Chain, &zero, nil, false, nil},
+		{"polling with a packet", []Program{poll}, Chain, &zero, []Packet{{From: -1, To: 0, Values: []int{3}}}, true, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machines := make([]*Interpreter, len(tt.programs))
+			for i, p := range tt.programs {
+				machines[i] = New(p, nil, nil)
+			}
+			n := NewNetwork(machines, tt.route)
+			n.EmptyInput = tt.emptyInput
+			for _, p := range tt.send {
+				n.Send(p)
+			}
+			if err := n.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if !n.Idle() {
+				t.Error("network isn't idle after Run")
+			}
+			if n.Halted() != tt.wantHalted {
+				t.Errorf("Halted() = %v, want %v", n.Halted(), tt.wantHalted)
+			}
+			var out []int
+			for _, p := range n.Receive() {
+				out = append(out, p.Values...)
+			}
+			if !reflect.DeepEqual(out, tt.wantOut) {
+				t.Errorf("network output %v, want %v", out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestNetworkWakes(t *testing.T) {
+	zero := 0
+	n := NewNetwork([]*Interpreter{New(poll, nil, nil)}, Chain)
+	n.EmptyInput = &zero
+	for step := 0; step < 3; step++ {
+		if err := n.Step(); err != nil {
+			t.Fatal(err)
+		}
+		if !n.Idle() || n.Halted() {
+			t.Fatalf("after step %d, Idle() = %v and Halted() = %v, want idle and not halted", step, n.Idle(), n.Halted())
+		}
+	}
+	n.Send(Packet{From: -1, To: 0, Values: []int{4}})
+	if n.Idle() {
+		t.Fatal("network is idle after a packet arrived")
+	}
+	if err := n.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !n.Halted() {
+		t.Fatal("network didn't halt after the packet arrived")
+	}
+}
@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// stateFormat names the format of saved machines, so that other JSON isn't mistaken for one.
+const stateFormat = "intcode state 1"
+
+// state is a machine as Save writes it.
+type state struct {
+	Format string `json:"format"`
+	Ipt    uint   `json:"ipt"`
+	Rpt    int    `json:"rpt"`
+	// Memory is memory from address 0, leaving out the zeroes at the end
+	Memory []int `json:"memory"`
+	// Far holds values written far past the end of Memory, by address
+	Far     map[uint]int `json:"far,omitempty"`
+	Inputs  []int        `json:"inputs,omitempty"`
+	Outputs []int        `json:"outputs,omitempty"`
+}
+
+// Save writes the machine's state as JSON: its memory, ipt and rpt, and its queued input and output.
+// Load makes a machine that carries on from where it was. Its input and output functions aren't saved.
+func (i *Interpreter) Save(w io.Writer) error {
+	cells := i.mem.cells()
+	for len(cells) > 0 && cells[len(cells)-1] == 0 {
+		cells = cells[:len(cells)-1]
+	}
+	s := state{
+		Format:  stateFormat,
+		Ipt:     i.ipt,
+		Rpt:     i.rpt,
+		Memory:  cells,
+		Inputs:  i.inputs,
+		Outputs: i.outputs,
+	}
+	for addr, v := range i.mem.far {
+		if v != 0 {
+			if s.Far == nil {
+				s.Far = make(map[uint]int)
+			}
+			s.Far[addr] = v
+		}
+	}
+	return json.NewEncoder(w).Encode(s)
+}
+
+// Load makes a machine from a state written by Save, with the given input and output functions,
+// which may be nil as for New.
+func Load(r io.Reader, input func() int, output func(int)) (*Interpreter, error) {
+	var s state
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("reading intcode state: %w", err)
+	}
+	if s.Format != stateFormat {
+		return nil, fmt.Errorf("not an intcode state: format is %q, not %q", s.Format, stateFormat)
+	}
+	i := &Interpreter{
+		mem:     newMemory(s.Memory),
+		ipt:     s.Ipt,
+		rpt:     s.Rpt,
+		input:   input,
+		output:  output,
+		inputs:  s.Inputs,
+		outputs: s.Outputs,
+	}
+	for addr, v := range s.Far {
+		i.set(addr, v)
+	}
+	return announce(i), nil
+}
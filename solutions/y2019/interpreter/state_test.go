@@ -0,0 +1,142 @@
+package interpreter
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// addFar reads a value into address 0, writes it plus 1 far past the end of the program, outputs that and halts.
+var addFar = Program{3, 0, 1001, 0, 1, 1000, 4, 1000, 99}
+
+// outputs runs a machine until it halts and returns what it output.
+func outputs(t *testing.T, i *Interpreter) []int {
+	t.Helper()
+	if _, err := i.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var out []int
+	for n, ok := i.PopOutput(); ok; n, ok = i.PopOutput() {
+		out = append(out, n)
+	}
+	return out
+}
+
+func TestCloneIsolation(t *testing.T) {
+	tests := []struct {
+		name                string
+		cloneFirst          bool
+		pokeOrig, pokeClone map[uint]int
+		wantOrig, wantClone int
+	}{
+		{"original runs first", false, nil, nil, 6, 8},
+		{"clone runs first", true, nil, nil, 6, 8},
+		// MUL instead of ADD, so the decoded instruction can't be shared either
+		{"original rewrites its code", false, map[uint]int{2: 1002}, nil, 5, 8},
+		{"clone rewrites its code", true, nil, map[uint]int{2: 1002}, 6, 7},
+		{"far writes", false, map[uint]int{2000: 1}, map[uint]int{3000: 1}, 6, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := New(addFar, nil, nil)
+			// stop at the input instruction, having decoded it
+			if e, err := orig.RunUntilEvent(); e != NeedInput || err != nil {
+				t.Fatalf("got %v, %v; want %v", e, err, NeedInput)
+			}
+			clone := orig.Clone()
+			for addr, v := range tt.pokeOrig {
+				orig.Poke(addr, v)
+			}
+			for addr, v := range tt.pokeClone {
+				clone.Poke(addr, v)
+			}
+			orig.PushInput(5)
+			clone.PushInput(7)
+
+			var gotOrig, gotClone []int
+			if tt.cloneFirst {
+				gotClone = outputs(t, clone)
+				gotOrig = outputs(t, orig)
+			} else {
+				gotOrig = outputs(t, orig)
+				gotClone = outputs(t, clone)
+			}
+			if !reflect.DeepEqual(gotOrig, []int{tt.wantOrig}) || !reflect.DeepEqual(gotClone, []int{tt.wantClone}) {
+				t.Errorf("original output %v and clone output %v, want %d and %d", gotOrig, gotClone, tt.wantOrig, tt.wantClone)
+			}
+			if orig.Peek(0) != 5 || clone.Peek(0) != 7 {
+				t.Errorf("address 0 is %d in the original and %d in the clone, want 5 and 7", orig.Peek(0), clone.Peek(0))
+			}
+			for addr := range tt.pokeOrig {
+				if _, ok := tt.pokeClone[addr]; !ok && clone.Peek(addr) != New(addFar, nil, nil).Peek(addr) {
+					t.Errorf("the original's write to %d shows in the clone", addr)
+				}
+			}
+			for addr := range tt.pokeClone {
+				if _, ok := tt.pokeOrig[addr]; !ok && orig.Peek(addr) != New(addFar, nil, nil).Peek(addr) {
+					t.Errorf("the clone's write to %d shows in the original", addr)
+				}
+			}
+		})
+	}
+}
+
+// saveProgram moves rpt, outputs 42, writes far past its end, then reads a value and outputs it.
+var saveProgram = Program{109, 7, 104, 42, 1101, 2, 3, 5000, 3, 0, 204, -7, 99}
+
+func TestSaveLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		events int
+		inputs []int
+	}{
+		{"before it starts", 0, nil},
+		{"with output queued", 1, nil},
+		{"waiting for input", 2, nil},
+		{"with input queued", 1, []int{8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := New(saveProgram, nil, nil)
+			for e := 0; e < tt.events; e++ {
+				if _, err := orig.RunUntilEvent(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			orig.PushInput(tt.inputs...)
+
+			var saved bytes.Buffer
+			if err := orig.Save(&saved); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := Load(bytes.NewReader(saved.Bytes()), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var resaved bytes.Buffer
+			if err := loaded.Save(&resaved); err != nil {
+				t.Fatal(err)
+			}
+			if resaved.String() != saved.String() {
+				t.Errorf("saving the loaded machine gave\n%s\nwant\n%s", resaved.String(), saved.String())
+			}
+
+			orig.PushInput(9)
+			loaded.PushInput(9)
+			want, got := outputs(t, orig), outputs(t, loaded)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("loaded machine output %v, want %v", got, want)
+			}
+			if got, want := loaded.Peek(5000), orig.Peek(5000); got != want {
+				t.Errorf("loaded machine has %d at 5000, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsOtherJSON(t *testing.T) {
+	if _, err := Load(strings.NewReader(`{"format": "something else"}`), nil, nil); err == nil {
+		t.Error("loaded a state with the wrong format")
+	}
+}
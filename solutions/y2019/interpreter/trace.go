@@ -0,0 +1,440 @@
+package interpreter
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// traceHeader starts every trace file, so that other files aren't mistaken for traces.
+const traceHeader = "intcode trace 1\n"
+
+// kinds of trace record
+const (
+	recStart  = 'M'
+	recStep   = 'S'
+	recWrite  = 'W'
+	recInput  = 'I'
+	recOutput = 'O'
+)
+
+// traceEvent is a record of something a machine did, other than starting.
+type traceEvent struct {
+	kind byte
+	// ipt and raw are the address and value of an instruction about to be executed
+	ipt uint
+	raw int
+	// operands are the resolved parameters of the instruction: the value read for a parameter that's read,
+	// and the address for one that's written to
+	operands []int
+	// addr is the address of a write
+	addr uint
+	// value is the value written, input or output
+	value int
+}
+
+func (e *traceEvent) String() string {
+	switch e.kind {
+	case recStep:
+		name, ok := mnemonics[decode(e.raw).code]
+		if !ok {
+			name = "unknown opcode"
+		}
+		return fmt.Sprintf("instruction %d (%s) at %d with operands %v", e.raw, name, e.ipt, e.operands)
+	case recWrite:
+		return fmt.Sprintf("write of %d to %d", e.value, e.addr)
+	case recInput:
+		return fmt.Sprintf("input of %d", e.value)
+	}
+	return fmt.Sprintf("output of %d", e.value)
+}
+
+func (e *traceEvent) equal(o *traceEvent) bool {
+	if e.kind != o.kind || e.ipt != o.ipt || e.raw != o.raw || e.addr != o.addr || e.value != o.value ||
+		len(e.operands) != len(o.operands) {
+		return false
+	}
+	for j := range e.operands {
+		if e.operands[j] != o.operands[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// operands resolves the parameters of an instruction about to be executed, the way a trace records them.
+// It stops at the first parameter that can't be resolved, since executing the instruction will fail there.
+func (i *Interpreter) operands(in *instr, buf []int) []int {
+	buf = buf[:0]
+	if _, ok := mnemonics[in.code]; !ok {
+		return buf
+	}
+	w, writes := writeParam[in.code]
+	for j := uint(0); j < paramCount(in.code); j++ {
+		var v int
+		var err error
+		if writes && j == w {
+			var addr uint
+			addr, err = i.addr(in, j)
+			v = int(addr)
+		} else {
+			v, err = i.read(in, j)
+		}
+		if err != nil {
+			break
+		}
+		buf = append(buf, v)
+	}
+	return buf
+}
+
+// Tracer records everything that the machines attached to it do to a trace: the state each machine starts in,
+// and then every instruction it executes with its resolved parameters, every write to memory, and every value
+// it inputs or outputs. Replay re-runs the machines in a trace to check that they do the same again.
+//
+// Traces are written compactly, as varints.
+type Tracer struct {
+	mu   sync.Mutex
+	w    *bufio.Writer
+	err  error
+	ids  map[*Interpreter]int
+	seen map[*Interpreter]bool
+	buf  [binary.MaxVarintLen64]byte
+	ops  []int
+}
+
+// NewTracer makes a tracer that writes a trace to w. Close it to finish the trace.
+func NewTracer(w io.Writer) *Tracer {
+	t := &Tracer{w: bufio.NewWriter(w), ids: make(map[*Interpreter]int), seen: make(map[*Interpreter]bool)}
+	_, t.err = t.w.WriteString(traceHeader)
+	return t
+}
+
+// Attach makes the tracer record a machine. Machines are numbered from 1 in the order they're attached.
+// Set OnNew to it to trace every machine that a solver makes.
+func (t *Tracer) Attach(i *Interpreter) {
+	t.mu.Lock()
+	t.ids[i] = len(t.ids) + 1
+	t.mu.Unlock()
+	i.Observe(t)
+}
+
+// Machines is the number of machines that have been attached.
+func (t *Tracer) Machines() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.ids)
+}
+
+// Close writes out what's left of the trace, and returns the first error writing it, if there was one.
+// It doesn't close the underlying writer.
+func (t *Tracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+	return t.err
+}
+
+func (t *Tracer) uvarint(n uint64) {
+	if t.err == nil {
+		_, t.err = t.w.Write(t.buf[:binary.PutUvarint(t.buf[:], n)])
+	}
+}
+
+func (t *Tracer) varint(n int) {
+	if t.err == nil {
+		_, t.err = t.w.Write(t.buf[:binary.PutVarint(t.buf[:], int64(n))])
+	}
+}
+
+func (t *Tracer) record(kind byte, i *Interpreter) {
+	if t.err == nil {
+		t.err = t.w.WriteByte(kind)
+	}
+	t.uvarint(uint64(t.ids[i]))
+}
+
+// Before records the machine's starting state the first time, and then the instruction it's about to execute.
+func (t *Tracer) Before(i *Interpreter) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.seen[i] {
+		t.seen[i] = true
+		t.record(recStart, i)
+		t.uvarint(uint64(i.ipt))
+		t.varint(i.rpt)
+		cells := i.mem.cells()
+		t.uvarint(uint64(len(cells)))
+		for _, v := range cells {
+			t.varint(v)
+		}
+		far := make([]uint, 0, len(i.mem.far))
+		for addr := range i.mem.far {
+			far = append(far, addr)
+		}
+		sort.Slice(far, func(a, b int) bool { return far[a] < far[b] })
+		t.uvarint(uint64(len(far)))
+		for _, addr := range far {
+			t.uvarint(uint64(addr))
+			t.varint(i.mem.far[addr])
+		}
+	}
+
+	in := i.mem.fetch(i.ipt)
+	t.ops = i.operands(&in, t.ops)
+	t.record(recStep, i)
+	t.uvarint(uint64(i.ipt))
+	t.varint(in.raw)
+	t.uvarint(uint64(len(t.ops)))
+	for _, v := range t.ops {
+		t.varint(v)
+	}
+	return nil
+}
+
+// Wrote records a write to memory.
+func (t *Tracer) Wrote(i *Interpreter, addr uint, old int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(recWrite, i)
+	t.uvarint(uint64(addr))
+	t.varint(i.get(addr))
+}
+
+// Input records a value that the machine read.
+func (t *Tracer) Input(i *Interpreter, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(recInput, i)
+	t.varint(n)
+}
+
+// Output records a value that the machine output.
+func (t *Tracer) Output(i *Interpreter, n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.record(recOutput, i)
+	t.varint(n)
+}
+
+// tracedMachine is a machine read back from a trace.
+type tracedMachine struct {
+	id     int
+	ipt    uint
+	rpt    int
+	cells  []int
+	far    map[uint]int
+	events []traceEvent
+}
+
+// readTrace reads the machines in a trace, in the order they started.
+func readTrace(r io.Reader) ([]*tracedMachine, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(traceHeader))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != traceHeader {
+		return nil, errors.New("not an intcode trace")
+	}
+
+	var machines []*tracedMachine
+	byID := make(map[int]*tracedMachine)
+	var err error
+	uvarint := func() uint64 {
+		var n uint64
+		if err == nil {
+			n, err = binary.ReadUvarint(br)
+		}
+		return n
+	}
+	varint := func() int {
+		var n int64
+		if err == nil {
+			n, err = binary.ReadVarint(br)
+		}
+		return int(n)
+	}
+	for {
+		kind, rerr := br.ReadByte()
+		if rerr == io.EOF {
+			return machines, nil
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+		id := int(uvarint())
+		if kind == recStart {
+			m := &tracedMachine{id: id, ipt: uint(uvarint()), rpt: varint()}
+			m.cells = make([]int, uvarint())
+			for j := range m.cells {
+				m.cells[j] = varint()
+			}
+			m.far = make(map[uint]int)
+			for n := uvarint(); n > 0 && err == nil; n-- {
+				addr := uint(uvarint())
+				m.far[addr] = varint()
+			}
+			if err == nil {
+				machines = append(machines, m)
+				byID[id] = m
+			}
+		} else {
+			m := byID[id]
+			e := traceEvent{kind: kind}
+			switch kind {
+			case recStep:
+				e.ipt = uint(uvarint())
+				e.raw = varint()
+				e.operands = make([]int, uvarint())
+				for j := range e.operands {
+					e.operands[j] = varint()
+				}
+			case recWrite:
+				e.addr = uint(uvarint())
+				e.value = varint()
+			case recInput, recOutput:
+				e.value = varint()
+			default:
+				return nil, fmt.Errorf("trace has a record of unknown kind %q", kind)
+			}
+			if m == nil && err == nil {
+				return nil, fmt.Errorf("trace has events for machine %d before it starts", id)
+			}
+			if err == nil {
+				m.events = append(m.events, e)
+			}
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading trace: %w", err)
+		}
+	}
+}
+
+// Divergence is the first thing a machine did differently when it was replayed.
+type Divergence struct {
+	// Machine is the number of the machine in the trace.
+	Machine int
+	// Event counts the machine's events before the one that differed.
+	Event int
+	// Want is the event in the trace, and Got is what the replay did instead. Either can say that there wasn't one.
+	Want, Got string
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("machine %d diverged after %d events: the trace has %s, but the replay has %s",
+		d.Machine, d.Event, d.Want, d.Got)
+}
+
+// ReplayStats counts what a replay covered.
+type ReplayStats struct {
+	Machines int
+	Events   int
+}
+
+// errTraceEnd stops a replayed machine once it has done everything the trace recorded it doing.
+var errTraceEnd = errors.New("end of the trace")
+
+// replayer is the observer of a replayed machine, checking what it does against the trace.
+type replayer struct {
+	want []traceEvent
+	pos  int
+	div  *Divergence
+	id   int
+	ops  []int
+}
+
+func (r *replayer) check(got traceEvent) {
+	if r.div != nil || r.pos >= len(r.want) {
+		return
+	}
+	if want := &r.want[r.pos]; !want.equal(&got) {
+		r.div = &Divergence{Machine: r.id, Event: r.pos, Want: want.String(), Got: got.String()}
+		return
+	}
+	r.pos++
+}
+
+func (r *replayer) Before(i *Interpreter) error {
+	if r.div != nil || r.pos >= len(r.want) {
+		return errTraceEnd
+	}
+	in := i.mem.fetch(i.ipt)
+	r.ops = i.operands(&in, r.ops)
+	r.check(traceEvent{kind: recStep, ipt: i.ipt, raw: in.raw, operands: append([]int(nil), r.ops...)})
+	if r.div != nil {
+		return errTraceEnd
+	}
+	return nil
+}
+
+func (r *replayer) Wrote(i *Interpreter, addr uint, old int) {
+	r.check(traceEvent{kind: recWrite, addr: addr, value: i.get(addr)})
+}
+
+func (r *replayer) Input(i *Interpreter, n int) {
+	r.check(traceEvent{kind: recInput, value: n})
+}
+
+func (r *replayer) Output(i *Interpreter, n int) {
+	r.check(traceEvent{kind: recOutput, value: n})
+}
+
+// Replay re-runs each machine in a trace from the state it started in, giving it the input that the trace
+// recorded it reading, and compares everything it does with the trace. It returns the first divergence,
+// or nil if every machine did the same again.
+//
+// Each machine is replayed by itself, so machines that ran at once and passed values between them
+// can be replayed even if their turns interleaved differently. A machine that stopped before halting
+// is only replayed as far as the trace goes.
+func Replay(r io.Reader) (*Divergence, ReplayStats, error) {
+	var stats ReplayStats
+	machines, err := readTrace(r)
+	if err != nil {
+		return nil, stats, err
+	}
+	for _, m := range machines {
+		var inputs []int
+		for _, e := range m.events {
+			if e.kind == recInput {
+				inputs = append(inputs, e.value)
+			}
+		}
+		rep := &replayer{want: m.events, id: m.id}
+		i := &Interpreter{mem: newMemory(m.cells), ipt: m.ipt, rpt: m.rpt, observer: rep}
+		for addr, v := range m.far {
+			i.set(addr, v)
+		}
+		i.input = func() int {
+			if len(inputs) == 0 {
+				// the replay has already diverged, by reading more than the trace did
+				return 0
+			}
+			n := inputs[0]
+			inputs = inputs[1:]
+			return n
+		}
+		i.output = func(int) {}
+
+		_, err := i.Run()
+		stats.Machines++
+		stats.Events += rep.pos
+		if rep.div != nil {
+			return rep.div, stats, nil
+		}
+		if rep.pos < len(rep.want) {
+			got := "the machine halting"
+			if err != nil {
+				got = fmt.Sprintf("an error: %v", err)
+			}
+			return &Divergence{Machine: m.id, Event: rep.pos, Want: rep.want[rep.pos].String(), Got: got}, stats, nil
+		}
+	}
+	return nil, stats, nil
+}
@@ -0,0 +1,84 @@
+package interpreter
+
+import (
+	"bytes"
+	"testing"
+)
+
+// quine is the day 9 example that outputs a copy of itself. It counts its outputs at address 100.
+var quine = Program{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}
+
+// traceQuine runs quine under a tracer and returns the trace. After each output, it calls tamper, if it's set,
+// with the number of outputs before it.
+func traceQuine(t *testing.T, tamper func(i *Interpreter, tr *Tracer, n int)) []byte {
+	var buf bytes.Buffer
+	tr := NewTracer(&buf)
+	i := New(quine, nil, nil)
+	tr.Attach(i)
+	for n := 0; ; n++ {
+		e, err := i.RunUntilEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e == Halted {
+			break
+		}
+		i.PopOutput()
+		if tamper != nil {
+			tamper(i, tr, n)
+		}
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(i *Interpreter, tr *Tracer, n int)
+		diverges bool
+	}{
+		{"untouched", nil, false},
+		{"memory changed outside the trace", func(i *Interpreter, tr *Tracer, n int) {
+			if n == 3 {
+				i.Poke(100, 10)
+			}
+		}, true},
+		{"instructions left out of the trace", func(i *Interpreter, tr *Tracer, n int) {
+			if n == 3 {
+				i.Observe(nil)
+				i.RunUntilEvent()
+				i.PopOutput()
+				i.Observe(tr)
+			}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			div, stats, err := Replay(bytes.NewReader(traceQuine(t, tt.tamper)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Machines != 1 || stats.Events == 0 {
+				t.Errorf("replayed %d machines and %d events, want 1 machine and some events", stats.Machines, stats.Events)
+			}
+			switch {
+			case tt.diverges && div == nil:
+				t.Error("replay didn't diverge")
+			case tt.diverges && div.Machine != 1:
+				t.Errorf("machine %d diverged, want machine 1", div.Machine)
+			case !tt.diverges && div != nil:
+				t.Errorf("replay diverged: %v", div)
+			}
+		})
+	}
+}
+
+func TestReplayTruncated(t *testing.T) {
+	trace := traceQuine(t, nil)
+	if _, _, err := Replay(bytes.NewReader(trace[:len(trace)-1])); err == nil {
+		t.Error("replaying a truncated trace didn't fail")
+	}
+}
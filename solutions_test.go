@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	_ "github.com/jzimbel/adventofcode-go/solutioninit"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/solutiontest"
+)
+
+// TestMain registers the external solvers, so that they're checked and benchmarked too.
+func TestMain(m *testing.M) {
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// TestSolutions checks every registered solver against its examples, generated inputs and ledger answers.
+func TestSolutions(t *testing.T) {
+	solutiontest.Run(t, solutions.Registry)
+}
+
+// BenchmarkSolutions times each part of every registered solver. adventofcode-go -bench runs it for a year or a day.
+func BenchmarkSolutions(b *testing.B) {
+	solutiontest.Bench(b, solutions.Registry)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jzimbel/adventofcode-go/input"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/tui"
+)
+
+// runTUI opens the full-screen terminal browser.
+func runTUI(args []string) int {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s tui\n", os.Args[0])
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := registerExternalSolvers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load external solvers: %v.\n", err)
+		return 1
+	}
+	// downloads would ask for the session id, which can't be done from the full-screen UI
+	errNoSession := errors.New("no session id has been saved; run a day from the command line first to set it")
+	err := tui.Run(tui.Options{
+		Registry: solutions.Registry,
+		Input: func(k solutions.Key) (string, error) {
+			if !input.Downloaded(k.Year, k.Day) && !input.SessionSaved() {
+				return "", errNoSession
+			}
+			return input.Get(k.Year, k.Day)
+		},
+		Puzzle: func(k solutions.Key, refresh bool) (string, error) {
+			if !input.SessionSaved() {
+				return "", errNoSession
+			}
+			return input.GetPuzzle(k.Year, k.Day, refresh)
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
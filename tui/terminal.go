@@ -0,0 +1,59 @@
+//go:build !windows
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// terminal puts the terminal in raw mode on the alternate screen, and restores it afterwards.
+// It shells out to stty rather than making the system calls itself.
+type terminal struct {
+	saved string
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func openTerminal() (*terminal, error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("can't control the terminal; is stdin a terminal? %w", err)
+	}
+	t := &terminal{saved}
+	return t, t.resume()
+}
+
+// resume takes over the terminal.
+func (t *terminal) resume() error {
+	if _, err := stty("raw", "-echo"); err != nil {
+		return err
+	}
+	fmt.Print(enterScreen)
+	return nil
+}
+
+// suspend gives the terminal back as it was, e.g. to run an editor.
+func (t *terminal) suspend() error {
+	fmt.Print(leaveScreen)
+	_, err := stty(t.saved)
+	return err
+}
+
+// size returns the width and height of the terminal.
+func (t *terminal) size() (width, height int) {
+	out, err := stty("size")
+	if err == nil {
+		if _, err := fmt.Sscan(out, &height, &width); err == nil && width > 0 && height > 0 {
+			return
+		}
+	}
+	return 80, 24
+}
@@ -0,0 +1,13 @@
+package tui
+
+import "errors"
+
+type terminal struct{}
+
+func openTerminal() (*terminal, error) {
+	return nil, errors.New("the terminal UI isn't supported on Windows")
+}
+
+func (t *terminal) resume() error             { return nil }
+func (t *terminal) suspend() error            { return nil }
+func (t *terminal) size() (width, height int) { return 80, 24 }
@@ -0,0 +1,474 @@
+// Package tui is a full-screen terminal browser for the registered solutions.
+//
+// A grid of years and days, colored by how many stars each day has, sits above a pane with the selected
+// day's answers, timings, diagnostics and puzzle text. Keys run and benchmark solvers and open their source.
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jzimbel/adventofcode-go/color"
+	"github.com/jzimbel/adventofcode-go/diag"
+	"github.com/jzimbel/adventofcode-go/history"
+	"github.com/jzimbel/adventofcode-go/ledger"
+	"github.com/jzimbel/adventofcode-go/solutions"
+	"github.com/jzimbel/adventofcode-go/solutions/examples"
+	"github.com/jzimbel/adventofcode-go/solutions/solutiontest"
+)
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+const help = "arrows/hjkl move  r run  . re-run last  b benchmark  p puzzle  P refresh puzzle  o open source  PgUp/PgDn scroll  q quit"
+
+// Options are what the terminal UI needs from the rest of the program.
+type Options struct {
+	Registry *solutions.Solvers
+	// Input loads a puzzle's input.
+	Input func(k solutions.Key) (string, error)
+	// Puzzle loads a puzzle's description as plain text, downloading it again if refresh is set.
+	Puzzle func(k solutions.Key, refresh bool) (string, error)
+}
+
+// keys other than plain characters
+const (
+	keyUp rune = -1 - iota
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyCtrlC rune = 3
+)
+
+var escapes = map[string]rune{
+	"\x1b[A": keyUp, "\x1b[B": keyDown, "\x1b[C": keyRight, "\x1b[D": keyLeft,
+	"\x1b[5~": keyPageUp, "\x1b[6~": keyPageDown,
+}
+
+// readKey waits for a key press.
+func readKey() (rune, error) {
+	buf := make([]byte, 16)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if k, ok := escapes[string(buf[:n])]; ok {
+		return k, nil
+	}
+	r, _ := utf8.DecodeRune(buf[:n])
+	return r, nil
+}
+
+// dayState is what the UI has found out about a day.
+type dayState struct {
+	run    []string
+	diags  []string
+	bench  []string
+	puzzle string
+}
+
+type ui struct {
+	opts   Options
+	term   *terminal
+	ledger *ledger.Ledger
+	years  []int
+	cursor solutions.Key
+	// last is the day that was last run, or the zero key if none has been
+	last   solutions.Key
+	states map[solutions.Key]*dayState
+	scroll int
+	status string
+}
+
+// Run shows the terminal UI until the user quits.
+func Run(opts Options) error {
+	l, err := ledger.Load()
+	if err != nil {
+		return err
+	}
+	u := &ui{opts: opts, ledger: l, states: make(map[solutions.Key]*dayState), status: help}
+
+	keys := opts.Registry.Keys()
+	seen := make(map[int]bool)
+	for _, k := range keys {
+		if !seen[k.Year] {
+			seen[k.Year] = true
+			u.years = append(u.years, k.Year)
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no solutions are registered")
+	}
+	sort.Ints(u.years)
+	u.cursor = keys[0]
+
+	if u.term, err = openTerminal(); err != nil {
+		return err
+	}
+	defer u.term.suspend()
+
+	for {
+		u.draw()
+		k, err := readKey()
+		if err != nil {
+			return err
+		}
+		if k == 'q' || k == keyCtrlC {
+			return nil
+		}
+		u.handle(k)
+	}
+}
+
+func (u *ui) state(k solutions.Key) *dayState {
+	if u.states[k] == nil {
+		u.states[k] = &dayState{}
+	}
+	return u.states[k]
+}
+
+func (u *ui) move(dYear, dDay int) {
+	i := sort.SearchInts(u.years, u.cursor.Year) + dYear
+	if i >= 0 && i < len(u.years) {
+		u.cursor.Year = u.years[i]
+	}
+	if d := u.cursor.Day + dDay; d >= solutions.FirstDay && d <= solutions.LastDay {
+		u.cursor.Day = d
+	}
+	u.scroll = 0
+}
+
+func (u *ui) handle(k rune) {
+	u.status = help
+	switch k {
+	case keyUp, 'k':
+		u.move(-1, 0)
+	case keyDown, 'j':
+		u.move(1, 0)
+	case keyLeft, 'h':
+		u.move(0, -1)
+	case keyRight, 'l':
+		u.move(0, 1)
+	case keyPageUp:
+		u.scroll -= 10
+	case keyPageDown:
+		u.scroll += 10
+	case 'r':
+		u.run(u.cursor)
+	case '.':
+		if u.last == (solutions.Key{}) {
+			u.status = "Nothing has been run yet."
+			return
+		}
+		u.cursor = u.last
+		u.run(u.last)
+	case 'b':
+		u.benchmark(u.cursor)
+	case 'p', 'P':
+		u.loadPuzzle(u.cursor, k == 'P')
+	case 'o':
+		u.open(u.cursor)
+	}
+}
+
+// busy shows a status message while something slow happens.
+func (u *ui) busy(format string, args ...interface{}) {
+	u.status = fmt.Sprintf(format, args...)
+	u.draw()
+}
+
+// solve runs a solver, turning a panic into an error so that the terminal gets restored.
+func solve(s solutions.Solver, input string, env *solutions.Env) (sol *solutions.Solution, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("solver panicked: %v", r)
+		}
+	}()
+	return s(input, env)
+}
+
+func (u *ui) run(k solutions.Key) {
+	e, ok := u.opts.Registry.Lookup(k.Year, k.Day)
+	if !ok {
+		u.status = fmt.Sprintf("There's no solution for %v yet.", k)
+		return
+	}
+	st := u.state(k)
+	u.busy("Running %v…", k)
+	d := diag.New(diag.Debug)
+	st.run, st.diags = nil, nil
+	defer func() {
+		for _, entry := range d.Entries() {
+			st.diags = append(st.diags, fmt.Sprintf("[%s] %s", entry.Level, entry.Msg))
+		}
+		u.last, u.status = k, help
+	}()
+
+	env, err := e.NewEnv(d, nil)
+	if err != nil {
+		st.run = []string{color.R("Error: " + err.Error())}
+		return
+	}
+	input, err := u.opts.Input(k)
+	if err != nil {
+		st.run = []string{color.R("Failed to load the input: " + err.Error())}
+		return
+	}
+	start := time.Now()
+	sol, err := solve(e.Solver, input, env)
+	elapsed := time.Since(start)
+	if err != nil {
+		st.run = []string{color.R("Error: " + err.Error())}
+		return
+	}
+	for i, a := range [...]interface{}{sol.Part1, sol.Part2} {
+		lines := strings.Split(fmt.Sprint(a), "\n")
+		st.run = append(st.run, fmt.Sprintf("Part %d: %s", i+1, color.G(lines[0])))
+		for _, line := range lines[1:] {
+			st.run = append(st.run, "        "+color.G(line))
+		}
+	}
+	st.run = append(st.run, fmt.Sprintf("Took %v", elapsed))
+	if err := history.Append(history.NewRun(k, 0, "", sol, elapsed)); err != nil {
+		st.diags = append(st.diags, "failed to record run history: "+err.Error())
+	}
+}
+
+func (u *ui) benchmark(k solutions.Key) {
+	bms, err := solutiontest.Benchmarks(u.opts.Registry, k)
+	if err != nil {
+		u.status = err.Error()
+		return
+	}
+	st := u.state(k)
+	st.bench = nil
+	u.busy("Benchmarking %v with go test…", k)
+	// go test fails if any benchmark did, but the rest still have results
+	out, err := solutiontest.BenchCommand(k.Year, k.Day).Output()
+	if err != nil && len(out) == 0 {
+		u.status = "Failed to run the benchmarks with go test: " + err.Error()
+		return
+	}
+	// results are lines like "BenchmarkSolutions/2019-02/part1-8  100  12345 ns/op  ..."
+	results := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "BenchmarkSolutions/") {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], "BenchmarkSolutions/")
+		if i := strings.LastIndexByte(name, '-'); i > strings.LastIndexByte(name, '/') {
+			name = name[:i]
+		}
+		results[name] = strings.Join(fields[1:], " ")
+	}
+	for i := range bms {
+		bm := &bms[i]
+		switch r, ok := results[bm.Name]; {
+		case bm.Skip != "":
+			st.bench = append(st.bench, fmt.Sprintf("part %d: skipped: %s", bm.Part, bm.Skip))
+		case !ok:
+			st.bench = append(st.bench, fmt.Sprintf("part %d: %s", bm.Part, color.R("failed")))
+		default:
+			st.bench = append(st.bench, fmt.Sprintf("part %d: %s on %s", bm.Part, r, bm.Source))
+		}
+	}
+	u.status = help
+}
+
+func (u *ui) loadPuzzle(k solutions.Key, refresh bool) {
+	u.busy("Loading the puzzle for %v…", k)
+	text, err := u.opts.Puzzle(k, refresh)
+	if err != nil {
+		u.status = "Failed to load the puzzle: " + err.Error()
+		return
+	}
+	u.state(k).puzzle = text
+	u.status = help
+}
+
+// sourceDir returns the directory of a day's package.
+func sourceDir(k solutions.Key) string {
+	return filepath.Join(examples.Root(), "solutions", fmt.Sprintf("y%d", k.Year), fmt.Sprintf("d%02d", k.Day))
+}
+
+// open opens a day's source in the user's editor, or shows where it is if they haven't set one.
+func (u *ui) open(k solutions.Key) {
+	dir := sourceDir(k)
+	if _, err := os.Stat(dir); err != nil {
+		u.status = fmt.Sprintf("%v has no source in %s.", k, dir)
+		return
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		u.status = "Source: " + dir + " (set $EDITOR to open it from here)"
+		return
+	}
+	path := dir
+	if _, err := os.Stat(filepath.Join(dir, "solution.go")); err == nil {
+		path = filepath.Join(dir, "solution.go")
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	u.term.suspend()
+	err := cmd.Run()
+	u.term.resume()
+	if err != nil {
+		u.status = "Editor failed: " + err.Error()
+	}
+}
+
+// cell renders a day in the grid, colored by its stars.
+func (u *ui) cell(k solutions.Key) string {
+	mark := " "
+	if k == u.cursor {
+		mark = ">"
+	}
+	if _, ok := u.opts.Registry.Lookup(k.Year, k.Day); !ok {
+		return mark + " ."
+	}
+	label := fmt.Sprintf("%02d", k.Day)
+	answers, _ := u.ledger.Get(k)
+	switch answers.Stars() {
+	case 2:
+		label = color.Y(label)
+	case 1:
+		label = color.B(label)
+	default:
+		label = color.R(label)
+	}
+	return mark + label
+}
+
+func (u *ui) gridLines() []string {
+	var b strings.Builder
+	b.WriteString("    ")
+	for d := solutions.FirstDay; d <= solutions.LastDay; d++ {
+		fmt.Fprintf(&b, "%3d", d)
+	}
+	lines := []string{b.String()}
+	for _, y := range u.years {
+		b.Reset()
+		fmt.Fprintf(&b, "%d", y)
+		for d := solutions.FirstDay; d <= solutions.LastDay; d++ {
+			b.WriteString(u.cell(solutions.Key{Year: y, Day: d}))
+		}
+		lines = append(lines, b.String())
+	}
+	legend := fmt.Sprintf("%s both stars  %s one star  %s no stars  . no solution", color.Y("##"), color.B("##"), color.R("##"))
+	return append(lines, legend)
+}
+
+// wrap breaks text into lines no wider than width.
+func wrap(text string, width int) (lines []string) {
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > width {
+			runes := []rune(line)
+			cut := width
+			if i := strings.LastIndex(string(runes[:width]), " "); i > 0 {
+				cut = utf8.RuneCountInString(string(runes[:width])[:i])
+			}
+			lines = append(lines, string(runes[:cut]))
+			line = strings.TrimLeft(string(runes[cut:]), " ")
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+func (u *ui) detailLines(width int) []string {
+	k := u.cursor
+	e, registered := u.opts.Registry.Lookup(k.Year, k.Day)
+	title := e.Title
+	if !registered {
+		title = "no solution yet"
+	}
+	lines := []string{color.B(fmt.Sprintf("%d day %d: %s", k.Year, k.Day, title))}
+
+	if answers, ok := u.ledger.Get(k); ok {
+		lines = append(lines, fmt.Sprintf("Stars: %s", strings.Repeat("*", answers.Stars())))
+		for i, a := range [...]*string{answers.Part1, answers.Part2} {
+			if a != nil {
+				lines = append(lines, fmt.Sprintf("Accepted part %d: %s", i+1, strings.Replace(*a, "\n", " / ", -1)))
+			}
+		}
+	}
+	if registered {
+		lines = append(lines, "Source: "+sourceDir(k))
+	}
+
+	st := u.states[k]
+	if st == nil {
+		return append(lines, "", "Press r to run it or p to show the puzzle.")
+	}
+	section := func(name string, body []string) {
+		if len(body) > 0 {
+			lines = append(lines, "", color.B(name))
+			lines = append(lines, body...)
+		}
+	}
+	section("Last run", st.run)
+	var diags []string
+	for _, d := range st.diags {
+		diags = append(diags, wrap(d, width)...)
+	}
+	section("Diagnostics", diags)
+	section("Benchmarks", st.bench)
+	if st.puzzle != "" {
+		section("Puzzle", wrap(st.puzzle, width))
+	}
+	return lines
+}
+
+func (u *ui) draw() {
+	width, height := u.term.size()
+	lines := append(u.gridLines(), "")
+	detail := u.detailLines(width)
+
+	room := height - len(lines) - 1
+	if room < 1 {
+		room = 1
+	}
+	if max := len(detail) - room; u.scroll > max {
+		u.scroll = max
+	}
+	if u.scroll < 0 {
+		u.scroll = 0
+	}
+	end := u.scroll + room
+	if end > len(detail) {
+		end = len(detail)
+	}
+	lines = append(lines, detail[u.scroll:end]...)
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	for i := range lines {
+		lines[i] = fit(lines[i], width)
+	}
+	fmt.Print(clearScreen + strings.Join(lines, "\r\n") + "\r\n" + fit(u.status, width))
+}
+
+// fit cuts a line down to the terminal's width so that it doesn't wrap and push the rest of the screen down.
+// Lines with color escapes are left alone, since the escapes don't take up any room.
+func fit(line string, width int) string {
+	if strings.ContainsRune(line, '\x1b') || utf8.RuneCountInString(line) <= width {
+		return line
+	}
+	return string([]rune(line)[:width])
+}